@@ -1,6 +1,14 @@
 import './style.css';
 import './app.css';
-import { SaveXLSXsToPDFDir } from '../wailsjs/go/internal/App';
+import { CheckXLSXs, ConvertXLSXs, DefaultFieldRules, PreviewPosting, SaveBlankTemplate, SaveCheckReport, SavePreviewPDF } from '../wailsjs/go/internal/App';
+
+// 変換できる入力ファイルの拡張子
+const inputExtensions = ['.xlsx', '.xlsm', '.xls', '.ods', '.csv', '.tsv', '.zip'];
+
+function isInputFile(name) {
+  const lower = name.toLowerCase();
+  return inputExtensions.some(ext => lower.endsWith(ext));
+}
 
 // ロゴなしのドラッグ&ドロップUI
 window.addEventListener('DOMContentLoaded', () => {
@@ -14,7 +22,50 @@ window.addEventListener('DOMContentLoaded', () => {
     </div>
     <div id="error-list" style="color:#b85c3b; margin-bottom:10px; font-size:0.97em;"></div>
     <ul id="file-list"></ul>
-    <button class="btn" id="sendBtn" style="margin-top:18px;">PDFに変換</button>
+    <div class="format-list" id="format-list">
+      <label><input type="checkbox" name="format" value="pdf" checked /> PDF</label>
+      <label><input type="checkbox" name="format" value="html" /> HTML</label>
+      <label><input type="checkbox" name="format" value="docx" /> Word</label>
+      <label><input type="checkbox" name="format" value="thumbnails" /> PNG</label>
+      <label><input type="checkbox" name="format" value="json" /> JSON</label>
+      <label><input type="checkbox" name="format" value="jsonld" /> JSON-LD</label>
+      <label><input type="checkbox" name="format" value="feed" /> 求人フィード(XML)</label>
+      <label><input type="checkbox" name="format" value="text" /> テキスト</label>
+      <label><input type="checkbox" name="format" value="markdown" /> Markdown</label>
+    </div>
+    <input type="text" id="feed-url" class="feed-url" placeholder="求人フィードの求人ページのURL（{id} は求人番号に置き換えます。例: https://example.co.jp/jobs/{id}）" />
+    <label class="sheet-layout"><input type="checkbox" id="sheet-layout" /> PDF・PNGをシートの書式（結合セル・列幅・罫線）どおりに出力</label>
+    <label class="image-slot">画像の配置（元の位置に置けない画像）
+      <select id="image-slot">
+        <option value="footer">求人票の末尾</option>
+        <option value="header">左上</option>
+        <option value="none">出力しない</option>
+      </select>
+    </label>
+    <div class="sheet-select">
+      <label>変換するシート
+        <select id="sheet-mode">
+          <option value="all">すべて</option>
+          <option value="visible">表示中のシートのみ</option>
+          <option value="pattern">名前のパターンに合うシート</option>
+          <option value="names">指定したシート</option>
+        </select>
+      </label>
+      <input type="text" id="sheet-filter" class="sheet-filter" style="display:none" />
+      <label><input type="checkbox" id="mail-merge" /> 差し込み（1行目が項目名の一覧を、1行1件の求人票にする）</label>
+      <label><input type="checkbox" id="pdf-per-sheet" /> PDFをシート（差し込みの場合は1行）ごとに分ける（ファイル名は入力ファイル名・職種・勤務地）</label>
+    </div>
+    <input type="text" id="char-limits" class="char-limits" placeholder="文字数制限（例: 仕事内容=400, 応募資格=200）" />
+    <textarea id="field-formats" class="field-formats" rows="2" placeholder="表示形式（1行に1つ。例: 給与下限=¥#,##0、応募締切=ggge年m月d日）"></textarea>
+    <div class="field-rules-box">
+      <textarea id="field-rules" class="field-rules" rows="4" placeholder="入力ルール（JSON。空の場合は既定のルール（すべて warning）。重大度 error の違反があるシートはどの形式でも出力しません）"></textarea>
+      <button class="btn" id="rulesBtn">既定のルールを読み込む</button>
+    </div>
+    <textarea id="batch-passwords" class="batch-passwords" rows="2" placeholder="パスワード付きファイル用の共通パスワード（1行に1つ）"></textarea>
+    <button class="btn" id="sendBtn" style="margin-top:18px;">変換</button>
+    <button class="btn" id="checkBtn" style="margin-top:18px;">チェックのみ</button>
+    <button class="btn" id="templateBtn" style="margin-top:18px;">入力用テンプレートを保存</button>
+    <div id="preview" class="preview"></div>
   `;
 
   const dropArea = document.getElementById('drop-area');
@@ -23,7 +74,10 @@ window.addEventListener('DOMContentLoaded', () => {
   const fileSelectBtn = document.getElementById('fileSelectBtn');
   const errorList = document.getElementById('error-list');
   const sendBtn = document.getElementById('sendBtn');
+  const preview = document.getElementById('preview');
   let lastXlsxFiles = [];
+  // ファイルごとのパスワード
+  const filePasswords = new Map();
 
   // ドラッグ時のスタイル変更
   ['dragenter', 'dragover'].forEach(eventName => {
@@ -43,6 +97,16 @@ window.addEventListener('DOMContentLoaded', () => {
 
   sendBtn.disabled = true;
 
+  // シートの選び方に応じて、パターン・シート名の入力欄を切り替える
+  const sheetMode = document.getElementById('sheet-mode');
+  const sheetFilter = document.getElementById('sheet-filter');
+  sheetMode.addEventListener('change', () => {
+    sheetFilter.style.display = ['pattern', 'names'].includes(sheetMode.value) ? 'block' : 'none';
+    sheetFilter.placeholder = sheetMode.value === 'pattern'
+      ? 'シート名のパターン（例: 求人*）'
+      : 'シート名（カンマ区切り。例: 営業職, 事務職）';
+  });
+
   dropArea.addEventListener('drop', handleDrop, false);
   fileSelectBtn.addEventListener('click', () => fileElem.click());
   fileElem.addEventListener('change', (e) => {
@@ -51,22 +115,214 @@ window.addEventListener('DOMContentLoaded', () => {
 
   sendBtn.addEventListener('click', async () => {
     if (lastXlsxFiles.length === 0) {
-      sendBtn.innerHTML = 'xlsxファイルが選択されていません';
+      sendBtn.innerHTML = 'ファイルが選択されていません';
+      return;
+    }
+    const fileDatas = await readFileDatas();
+    // 出力形式（チェックボックス）
+    const options = {};
+    document.querySelectorAll('input[name="format"]').forEach(input => {
+      options[input.value] = input.checked;
+    });
+    if (!Object.values(options).some(Boolean)) {
+      sendBtn.innerHTML = '出力形式が選択されていません';
+      return;
+    }
+    try {
+      Object.assign(options, readOptions());
+      options.pdfPerSheet = document.getElementById('pdf-per-sheet').checked;
+      options.charLimits = parseCharLimits(document.getElementById('char-limits').value);
+      options.feedUrl = document.getElementById('feed-url').value.trim();
+      const result = await ConvertXLSXs(fileDatas, options);
+      showWarnings(result.warnings || []);
+      const blocked = result.blocked || [];
+      sendBtn.innerHTML = blocked.length > 0
+        ? `変換しました（入力エラーのため ${blocked.length} シートを出力しませんでした）`
+        : '変換しました';
+    } catch (e) {
+      sendBtn.innerHTML = 'エラー: ' + e;
+      // パスワードの誤り・未入力は、どのファイルか分かるように一覧の上にも表示
+      if (String(e).includes('パスワード')) {
+        showWarnings([String(e)]);
+      }
+    }
+  });
+
+  // PDFを保存せずに描画だけ行い、出力されない文字列や空の必須項目などを一覧にする
+  const checkBtn = document.getElementById('checkBtn');
+  checkBtn.addEventListener('click', async () => {
+    if (lastXlsxFiles.length === 0) {
+      checkBtn.innerHTML = 'ファイルが選択されていません';
       return;
     }
-    // ファイルをBase64でまとめてGoに送信
-    const fileDatas = await Promise.all(lastXlsxFiles.map(async (file) => {
+    preview.textContent = 'チェックしています...';
+    try {
+      const report = await CheckXLSXs(await readFileDatas(), readOptions());
+      showWarnings(report.warnings || []);
+      showCheckReport(report);
+      checkBtn.innerHTML = 'チェックのみ';
+    } catch (e) {
+      preview.textContent = 'エラー: ' + e;
+    }
+  });
+
+  // 事前チェックの結果を、シートごとのページ数と問題の一覧の表で表示
+  function showCheckReport(report) {
+    preview.innerHTML = '';
+    const sheets = report.sheets || [];
+    const issues = report.issues || [];
+    preview.appendChild(checkTable(['ファイル', 'シート', 'ページ数', '問題の数'],
+      sheets.map(s => [s.file, s.sheet, s.pages, s.issues])));
+    if (issues.length > 0) {
+      preview.appendChild(checkTable(['ファイル', 'シート', '項目', 'セル', '問題', '詳細'],
+        issues.map(i => [i.file, i.sheet, i.field, i.cell, i.kind, i.detail])));
+    } else {
+      const p = document.createElement('p');
+      p.textContent = '問題は見つかりませんでした';
+      preview.appendChild(p);
+    }
+    ['xlsx', 'csv'].forEach(format => {
+      const btn = document.createElement('button');
+      btn.className = 'btn check-export';
+      btn.textContent = format.toUpperCase() + 'で保存';
+      btn.addEventListener('click', async () => {
+        try {
+          const path = await SaveCheckReport(report, format);
+          btn.textContent = '保存しました: ' + path;
+        } catch (e) {
+          btn.textContent = 'エラー: ' + e;
+        }
+      });
+      preview.appendChild(btn);
+    });
+  }
+
+  function checkTable(headers, rows) {
+    const table = document.createElement('table');
+    table.className = 'check-table';
+    const head = table.insertRow();
+    headers.forEach(h => {
+      const th = document.createElement('th');
+      th.textContent = h;
+      head.appendChild(th);
+    });
+    rows.forEach(row => {
+      const tr = table.insertRow();
+      row.forEach(v => {
+        tr.insertCell().textContent = v;
+      });
+    });
+    return table;
+  }
+
+  // ファイルをBase64にしてGoに渡す形にする
+  function readFileDatas() {
+    return Promise.all(lastXlsxFiles.map(async (file) => {
       const data = await fileToBase64(file);
-      return { name: file.name, data };
+      return { name: file.name, data, password: filePasswords.get(file) || '' };
     }));
+  }
+
+  // 現行の項目マッピングから入力用のxlsxテンプレートを作成
+  const templateBtn = document.getElementById('templateBtn');
+  templateBtn.addEventListener('click', async () => {
     try {
-      await SaveXLSXsToPDFDir(fileDatas);
-      sendBtn.innerHTML = '変換しました';
+      const path = await SaveBlankTemplate();
+      templateBtn.innerHTML = '保存しました: ' + path;
     } catch (e) {
-      sendBtn.innerHTML = 'エラー: ' + e;
+      templateBtn.innerHTML = 'エラー: ' + e;
     }
   });
 
+  // 読み込みと描画の設定（変換とプレビューで共通）
+  function readOptions() {
+    return {
+      passwords: batchPasswords(),
+      formats: parseFieldFormats(document.getElementById('field-formats').value),
+      sheetLayout: document.getElementById('sheet-layout').checked,
+      imageSlot: document.getElementById('image-slot').value,
+      sheets: readSheetSelection(),
+      mailMerge: document.getElementById('mail-merge').checked,
+      rules: parseFieldRules(document.getElementById('field-rules').value),
+    };
+  }
+
+  // 入力ルール（JSONの配列）を読み取る。空の場合は既定のルールを使うため undefined にする
+  function parseFieldRules(text) {
+    if (!text.trim()) return undefined;
+    let rules;
+    try {
+      rules = JSON.parse(text);
+    } catch (e) {
+      throw '入力ルールのJSONが正しくありません: ' + e.message;
+    }
+    if (!Array.isArray(rules)) {
+      throw '入力ルールはJSONの配列で指定してください';
+    }
+    return rules;
+  }
+
+  // 既定の入力ルールを編集できるように読み込む
+  const rulesBtn = document.getElementById('rulesBtn');
+  rulesBtn.addEventListener('click', async () => {
+    const rules = await DefaultFieldRules();
+    document.getElementById('field-rules').value = JSON.stringify(rules, null, 2);
+  });
+
+  // 変換するシートの条件
+  function readSheetSelection() {
+    const value = sheetFilter.value.trim();
+    switch (sheetMode.value) {
+      case 'visible':
+        return { visibleOnly: true };
+      case 'pattern':
+        return { pattern: value };
+      case 'names':
+        return { names: value.split(/[,、，\n]/).map(s => s.trim()).filter(Boolean) };
+    }
+    return {};
+  }
+
+  // 「項目名=文字数」をカンマ区切りで指定した文字数制限を読み取る
+  function parseCharLimits(text) {
+    const limits = {};
+    text.split(/[,、，\n]/).forEach(part => {
+      const [key, value] = part.split(/[=＝:：]/).map(s => s && s.trim());
+      const n = parseInt(value, 10);
+      if (key && n > 0) limits[key] = n;
+    });
+    return limits;
+  }
+
+  // 「項目名=表示形式」を1行に1つ指定した表示形式を読み取る（表示形式にカンマを含むため行で区切る）
+  function parseFieldFormats(text) {
+    const formats = {};
+    text.split('\n').forEach(line => {
+      const i = line.search(/[=＝]/);
+      if (i <= 0) return;
+      const key = line.slice(0, i).trim();
+      const code = line.slice(i + 1).trim();
+      if (key && code) formats[key] = code;
+    });
+    return formats;
+  }
+
+  // 共通パスワード（1行に1つ）
+  function batchPasswords() {
+    return document.getElementById('batch-passwords').value.split('\n').map(s => s.trim()).filter(Boolean);
+  }
+
+  // 変換時の注意事項を表示
+  function showWarnings(warnings) {
+    errorList.innerHTML = '';
+    errorList.style.display = warnings.length > 0 ? 'block' : 'none';
+    warnings.forEach(w => {
+      const div = document.createElement('div');
+      div.textContent = w;
+      errorList.appendChild(div);
+    });
+  }
+
   function fileToBase64(file) {
     return new Promise((resolve, reject) => {
       const reader = new FileReader();
@@ -90,6 +346,8 @@ window.addEventListener('DOMContentLoaded', () => {
       entries = entries.filter(Boolean);
       if (entries.length > 0) {
         fileList.innerHTML = '';
+        filePasswords.clear();
+        preview.innerHTML = '';
         errorList.innerHTML = '';
         lastXlsxFiles = [];
         entries.forEach(entry => traverseFileTree(entry));
@@ -99,18 +357,89 @@ window.addEventListener('DOMContentLoaded', () => {
     handleFiles(dt.files);
   }
 
+  // ファイル一覧に追加。クリックでプレビューを表示
+  function addFileItem(file, label) {
+    const li = document.createElement('li');
+    const name = document.createElement('span');
+    name.textContent = label;
+    li.appendChild(name);
+    li.title = 'クリックでプレビュー';
+    li.addEventListener('click', () => showPreview(file));
+    // パスワード付きのファイル用（空欄なら共通パスワードを試す）
+    const password = document.createElement('input');
+    password.type = 'password';
+    password.className = 'file-password';
+    password.placeholder = 'パスワード';
+    password.addEventListener('click', (e) => e.stopPropagation());
+    password.addEventListener('input', () => filePasswords.set(file, password.value));
+    li.appendChild(password);
+    fileList.appendChild(li);
+  }
+
+  // 保存前の確認用に、変換結果の画像と読み取った値を表示し、確認したPDFを保存できるようにする
+  async function showPreview(file) {
+    preview.textContent = 'プレビューを作成しています...';
+    try {
+      const data = await fileToBase64(file);
+      const result = await PreviewPosting({ name: file.name, data, password: filePasswords.get(file) || '' }, readOptions());
+      preview.innerHTML = '';
+      showWarnings(result.warnings || []);
+      if (result.pdf) {
+        const saveBtn = document.createElement('button');
+        saveBtn.className = 'btn';
+        saveBtn.textContent = 'このPDFを保存';
+        saveBtn.addEventListener('click', async () => {
+          try {
+            const path = await SavePreviewPDF(result);
+            saveBtn.textContent = '保存しました: ' + path;
+          } catch (e) {
+            saveBtn.textContent = 'エラー: ' + e;
+          }
+        });
+        preview.appendChild(saveBtn);
+      }
+      (result.pages || []).forEach(src => {
+        const img = document.createElement('img');
+        img.src = src;
+        img.className = 'preview-page';
+        preview.appendChild(img);
+      });
+      (result.sheets || []).forEach(sections => {
+        const dl = document.createElement('dl');
+        dl.className = 'preview-fields';
+        (sections || []).forEach(section => {
+          (section.items || []).forEach(item => {
+            const values = (item.values || []).filter(Boolean);
+            if (values.length === 0) return;
+            const dt = document.createElement('dt');
+            dt.textContent = item.label;
+            dl.appendChild(dt);
+            values.forEach(value => {
+              const dd = document.createElement('dd');
+              dd.textContent = value;
+              dl.appendChild(dd);
+            });
+          });
+        });
+        preview.appendChild(dl);
+      });
+    } catch (e) {
+      preview.textContent = 'エラー: ' + e;
+    }
+  }
+
   function handleFiles(files) {
     fileList.innerHTML = '';
+    filePasswords.clear();
+    preview.innerHTML = '';
     errorList.innerHTML = '';
-    sendBtn.innerHTML = 'PDFに変換';
+    sendBtn.innerHTML = '変換';
     const nonXlsxFiles = [];
     const xlsxFiles = [];
     Array.from(files).forEach(file => {
-      if (file.name.toLowerCase().endsWith('.xlsx')) {
+      if (isInputFile(file.name)) {
         xlsxFiles.push(file);
-        const li = document.createElement('li');
-        li.textContent = file.webkitRelativePath || file.name;
-        fileList.appendChild(li);
+        addFileItem(file, file.webkitRelativePath || file.name);
       } else {
         nonXlsxFiles.push(file);
       }
@@ -120,7 +449,7 @@ window.addEventListener('DOMContentLoaded', () => {
       sendBtn.disabled = true;
       const names = nonXlsxFiles.map(f => (f.webkitRelativePath || f.name)).join('<br>');
       errorList.style.display = 'block';
-      errorList.innerHTML = 'xlsx以外のファイルが含まれています:<br>' + names;
+      errorList.innerHTML = '対応していないファイルが含まれています（' + inputExtensions.join(' / ') + '）:<br>' + names;
     } else {
       sendBtn.disabled = false;
       errorList.style.display = 'none';
@@ -129,14 +458,12 @@ window.addEventListener('DOMContentLoaded', () => {
 
   // フォルダ内のファイルも再帰的に取得
   function traverseFileTree(item, path = "") {
-    sendBtn.innerHTML = 'PDFに変換';
+    sendBtn.innerHTML = '変換';
     sendBtn.disabled = false;
     if (item.isFile) {
       item.file(file => {
-        if (file.name.toLowerCase().endsWith('.xlsx')) {
-          const li = document.createElement('li');
-          li.textContent = path + file.name;
-          fileList.appendChild(li);
+        if (isInputFile(file.name)) {
+          addFileItem(file, path + file.name);
           lastXlsxFiles.push(file);
         } else {
           // 画面上にエラー表示
@@ -144,7 +471,7 @@ window.addEventListener('DOMContentLoaded', () => {
           const msg = path + file.name;
           if (!errorList.innerHTML.includes(msg)) {
             if (errorList.innerHTML === '' || errorList.style.display === 'none') {
-              errorList.innerHTML = 'xlsx以外のファイルが含まれています:<br>';
+              errorList.innerHTML = '対応していないファイルが含まれています（' + inputExtensions.join(' / ') + '）:<br>';
               errorList.style.display = 'block';
             }
             errorList.innerHTML += msg + '<br>';
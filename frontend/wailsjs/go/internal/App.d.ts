@@ -2,4 +2,18 @@
 // This file is automatically generated. DO NOT EDIT
 import {internal} from '../models';
 
+export function CheckXLSXs(arg1:Array<internal.FileData>,arg2:internal.ConvertOptions):Promise<internal.CheckReport>;
+
+export function ConvertXLSXs(arg1:Array<internal.FileData>,arg2:internal.ConvertOptions):Promise<internal.ConvertResult>;
+
+export function DefaultFieldRules():Promise<Array<internal.FieldRule>>;
+
+export function PreviewPosting(arg1:internal.FileData,arg2:internal.ConvertOptions):Promise<internal.PostingPreview>;
+
+export function SaveBlankTemplate():Promise<string>;
+
+export function SaveCheckReport(arg1:internal.CheckReport,arg2:string):Promise<string>;
+
+export function SavePreviewPDF(arg1:internal.PostingPreview):Promise<string>;
+
 export function SaveXLSXsToPDFDir(arg1:Array<internal.FileData>):Promise<void>;
@@ -2,6 +2,34 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function CheckXLSXs(arg1, arg2) {
+  return window['go']['internal']['App']['CheckXLSXs'](arg1, arg2);
+}
+
+export function ConvertXLSXs(arg1, arg2) {
+  return window['go']['internal']['App']['ConvertXLSXs'](arg1, arg2);
+}
+
+export function DefaultFieldRules() {
+  return window['go']['internal']['App']['DefaultFieldRules']();
+}
+
+export function PreviewPosting(arg1, arg2) {
+  return window['go']['internal']['App']['PreviewPosting'](arg1, arg2);
+}
+
+export function SaveBlankTemplate() {
+  return window['go']['internal']['App']['SaveBlankTemplate']();
+}
+
+export function SaveCheckReport(arg1, arg2) {
+  return window['go']['internal']['App']['SaveCheckReport'](arg1, arg2);
+}
+
+export function SavePreviewPDF(arg1) {
+  return window['go']['internal']['App']['SavePreviewPDF'](arg1);
+}
+
 export function SaveXLSXsToPDFDir(arg1) {
   return window['go']['internal']['App']['SaveXLSXsToPDFDir'](arg1);
 }
@@ -1,8 +1,191 @@
 export namespace internal {
 	
+	export class CheckIssue {
+	    file: string;
+	    sheet: string;
+	    field: string;
+	    cell: string;
+	    kind: string;
+	    detail: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CheckIssue(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.file = source["file"];
+	        this.sheet = source["sheet"];
+	        this.field = source["field"];
+	        this.cell = source["cell"];
+	        this.kind = source["kind"];
+	        this.detail = source["detail"];
+	    }
+	}
+	export class CheckSheet {
+	    file: string;
+	    sheet: string;
+	    pages: number;
+	    issues: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CheckSheet(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.file = source["file"];
+	        this.sheet = source["sheet"];
+	        this.pages = source["pages"];
+	        this.issues = source["issues"];
+	    }
+	}
+	export class CheckReport {
+	    sheets: CheckSheet[];
+	    issues: CheckIssue[];
+	    warnings: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new CheckReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.sheets = this.convertValues(source["sheets"], CheckSheet);
+	        this.issues = this.convertValues(source["issues"], CheckIssue);
+	        this.warnings = source["warnings"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ConvertOptions {
+	    pdf: boolean;
+	    html: boolean;
+	    docx: boolean;
+	    json: boolean;
+	    jsonld: boolean;
+	    feed: boolean;
+	    text: boolean;
+	    markdown: boolean;
+	    feedUrl: string;
+	    charLimits: {[key: string]: number};
+	    passwords: string[];
+	    formats: {[key: string]: string};
+	    sheetLayout: boolean;
+	    imageSlot: string;
+	    sheets: SheetSelection;
+	    pdfPerSheet: boolean;
+	    mailMerge: boolean;
+	    rules: FieldRule[];
+	    thumbnails: boolean;
+	    thumbnailDpi: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ConvertOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.pdf = source["pdf"];
+	        this.html = source["html"];
+	        this.docx = source["docx"];
+	        this.json = source["json"];
+	        this.jsonld = source["jsonld"];
+	        this.feed = source["feed"];
+	        this.text = source["text"];
+	        this.markdown = source["markdown"];
+	        this.feedUrl = source["feedUrl"];
+	        this.charLimits = source["charLimits"];
+	        this.passwords = source["passwords"];
+	        this.formats = source["formats"];
+	        this.sheetLayout = source["sheetLayout"];
+	        this.imageSlot = source["imageSlot"];
+	        this.sheets = this.convertValues(source["sheets"], SheetSelection);
+	        this.pdfPerSheet = source["pdfPerSheet"];
+	        this.mailMerge = source["mailMerge"];
+	        this.rules = this.convertValues(source["rules"], FieldRule);
+	        this.thumbnails = source["thumbnails"];
+	        this.thumbnailDpi = source["thumbnailDpi"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ConvertResult {
+	    files: string[];
+	    warnings: string[];
+	    blocked: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ConvertResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.files = source["files"];
+	        this.warnings = source["warnings"];
+	        this.blocked = source["blocked"];
+	    }
+	}
+	export class FieldRule {
+	    field: string;
+	    severity: string;
+	    required: boolean;
+	    pattern: string;
+	    maxLength: number;
+	    allowed: string[];
+	    lessOrEqual: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FieldRule(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.field = source["field"];
+	        this.severity = source["severity"];
+	        this.required = source["required"];
+	        this.pattern = source["pattern"];
+	        this.maxLength = source["maxLength"];
+	        this.allowed = source["allowed"];
+	        this.lessOrEqual = source["lessOrEqual"];
+	    }
+	}
 	export class FileData {
 	    name: string;
 	    data: string;
+	    password: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new FileData(source);
@@ -12,8 +195,110 @@ export namespace internal {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.name = source["name"];
 	        this.data = source["data"];
+	        this.password = source["password"];
+	    }
+	}
+	export class PostingItem {
+	    label: string;
+	    values: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new PostingItem(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.label = source["label"];
+	        this.values = source["values"];
+	    }
+	}
+	export class PostingSection {
+	    title: string;
+	    items: PostingItem[];
+	
+	    static createFrom(source: any = {}) {
+	        return new PostingSection(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.title = source["title"];
+	        this.items = this.convertValues(source["items"], PostingItem);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PostingPreview {
+	    name: string;
+	    pages: string[];
+	    pdf: string;
+	    fileName: string;
+	    sheets: PostingSection[][];
+	    warnings: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new PostingPreview(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.pages = source["pages"];
+	        this.pdf = source["pdf"];
+	        this.fileName = source["fileName"];
+	        this.sheets = this.convertValues(source["sheets"], PostingSection);
+	        this.warnings = source["warnings"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SheetSelection {
+	    visibleOnly: boolean;
+	    pattern: string;
+	    names: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SheetSelection(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.visibleOnly = source["visibleOnly"];
+	        this.pattern = source["pattern"];
+	        this.names = source["names"];
 	    }
 	}
 
 }
-
@@ -6,8 +6,11 @@ import (
 	"log"
 	"os"
 	"os/user"
+	"path"
 	"path/filepath"
+	"strings"
 	"time"
+	"unicode"
 
 	// "myapp/internal/pdf"
 
@@ -17,8 +20,9 @@ import (
 // FileData: フロントエンドから受け取るファイル情報
 // DataはBase64エンコードされたファイル内容
 type FileData struct {
-	Name string `json:"name"`
-	Data string `json:"data"`
+	Name     string `json:"name"`
+	Data     string `json:"data"`
+	Password string `json:"password"` // パスワード付きのファイルを開くためのパスワード（任意）
 }
 
 // App struct
@@ -64,25 +68,37 @@ type Table struct {
 	initialpageNum int        // ページ数
 	pageNum        int        // 描画準備時のページ数管理
 	titleW         float64    // タイトルの幅
+	outLine        bool       // Render時に外枠を描画したか
+
+	problems []layoutProblem // 出力できなかったセル（事前チェック用）
+}
+
+// layoutProblem: 幅やページ数の制限で出力できなかったセル
+type layoutProblem struct {
+	col_i, row_i int    // セルの開始位置（表の列・行）
+	kind         string // 理由（issueTooWide など）
+	text         string
 }
 
 type CellInfo struct {
-	x         float64 // X座標
-	y         float64 // Y座標
-	w         float64 // 幅
-	h         float64 // 高さ
-	pageNum   int     // ページ数
-	border    string  // セルの枠線スタイル（"0" = なし, "1" = 枠線）
-	col_i     int     // 開始列インデックス
-	row_i     int     // 開始行インデックス
-	col_f     int     // 終了列インデックス
-	row_f     int     // 終了行インデックス
-	text      string  // セルのテキスト
-	align     string  // テキストの配置
-	fill      bool    // 塗りつぶしフラグ
-	fontSize  float64 // フォントサイズ
-	link      string  // リンクURL
-	LineWidth float64 // 線の太さ
+	x         float64   // X座標
+	y         float64   // Y座標
+	w         float64   // 幅
+	h         float64   // 高さ
+	pageNum   int       // ページ数
+	border    string    // セルの枠線スタイル（"0" = なし, "1" = 枠線）
+	col_i     int       // 開始列インデックス
+	row_i     int       // 開始行インデックス
+	col_f     int       // 終了列インデックス
+	row_f     int       // 終了行インデックス
+	text      string    // セルのテキスト
+	align     string    // テキストの配置
+	fill      bool      // 塗りつぶしフラグ
+	fontSize  float64   // フォントサイズ
+	link      string    // リンクURL
+	LineWidth float64   // 線の太さ
+	fillColor string    // 塗りつぶしの色（"RRGGBB"。空の場合は描画時の色）
+	runs      []TextRun // 書式付きの文字列（text を書式ごとに分けたもの。書式がない場合は nil）
 }
 
 type RectInfo struct {
@@ -93,6 +109,7 @@ type RectInfo struct {
 	pageNum   int     // ページ数
 	style     string  // スタイル（"F" = 塗りつぶし, "D" = 枠線）
 	LineWidth float64 // 線の太さ
+	fillColor string  // 塗りつぶしの色（"RRGGBB"。空の場合は描画時の色）
 }
 
 type Row struct {
@@ -254,6 +271,7 @@ func (t *Table) SetCell(col_i, row_i, col_f, row_f int, text string, align strin
 	w := t.Xs[col_f] - t.Xs[col_i]
 	if t.pdf.GetStringWidth(text) > w {
 		fmt.Print("[Render] Invalid table: text width exceeds column width\n")
+		t.problems = append(t.problems, layoutProblem{col_i, row_i, issueTooWide, text})
 		return
 	}
 
@@ -311,11 +329,18 @@ func (t *Table) SetCell(col_i, row_i, col_f, row_f int, text string, align strin
 		})
 	} else { // 現在のページに収まらない場合
 		fmt.Print("[Render] Current page exceeds page height\n")
+		t.problems = append(t.problems, layoutProblem{col_i, row_i, issueOffPage, text})
 	}
 	fmt.Print("[Render] SetCell completed: ", text, " at (", col_i, ",", row_i, ") to (", col_f, ",", row_f, ")\n")
 }
 
 func (t *Table) SetMultiRowCell(col_i, row_i, col_f, row_f int, text string, align string, fill bool, fontSize float64, breakLines bool) {
+	t.SetMultiRowRichCell(col_i, row_i, col_f, row_f, []TextRun{{Text: text}}, align, fill, fontSize, breakLines)
+}
+
+// SetMultiRowRichCell は書式付きの文字列を SetMultiRowCell と同じように配置する。折り返しは書式の区切りをまたいで行う
+func (t *Table) SetMultiRowRichCell(col_i, row_i, col_f, row_f int, runs []TextRun, align string, fill bool, fontSize float64, breakLines bool) {
+	text := runsText(runs)
 
 	// 未生成のrow_iは無効
 	fmt.Print("[Render] SetMultiRowCell called: ", text, " at (", col_i, ",", row_i, ") to (", col_f, ",", row_f, ")\n")
@@ -331,12 +356,12 @@ func (t *Table) SetMultiRowCell(col_i, row_i, col_f, row_f int, text string, ali
 
 	w := t.Xs[col_f] - t.Xs[col_i]
 
-	// 行数を計算
-	t.pdf.SetFontSize(fontSize)
+	// 行数を計算（行の高さは最も大きい文字に合わせる）
+	t.pdf.SetFontSize(fontSize * maxRunScale(runs))
 	_, unitSize := t.pdf.GetFontSize()
-	lines := SplitByMaxChars(t.pdf, text, w, fontSize)
+	lines := SplitRunsByMaxChars(t.pdf, runs, w, fontSize)
 	if len(lines) == 0 {
-		lines = []string{""} // 空のセルを作成
+		lines = [][]TextRun{{}} // 空のセルを作成
 	}
 
 	// 余白を設定
@@ -349,6 +374,7 @@ func (t *Table) SetMultiRowCell(col_i, row_i, col_f, row_f int, text string, ali
 	// 2ページ以上にわたる場合は無効
 	if t.Ys[row_i]+default_Margin+float64(len(lines))*unitSize > pageHeight*2 {
 		fmt.Print("[Render] Invalid table: text height exceeds page height\n")
+		t.problems = append(t.problems, layoutProblem{col_i, row_i, issueTooLong, text})
 		return
 	}
 
@@ -377,7 +403,8 @@ func (t *Table) SetMultiRowCell(col_i, row_i, col_f, row_f int, text string, ali
 			row_i:     row_i,
 			col_f:     col_f,
 			row_f:     row_f,
-			text:      lines[i],
+			text:      runsText(lines[i]),
+			runs:      styledRuns(lines[i]),
 			align:     align,
 			fill:      fill,
 			fontSize:  fontSize,
@@ -432,7 +459,8 @@ func (t *Table) SetMultiRowCell(col_i, row_i, col_f, row_f int, text string, ali
 				row_i:     row_i,
 				col_f:     col_f,
 				row_f:     row_f,
-				text:      lines[i],
+				text:      runsText(lines[i]),
+				runs:      styledRuns(lines[i]),
 				align:     align,
 				fill:      fill,
 				fontSize:  fontSize,
@@ -513,6 +541,7 @@ func (t *Table) SetAppendix(text string, align string, fill bool, fontSize float
 	// 2ページ以上にわたる場合は無効
 	_, pageHeight := t.pdf.GetPageSize()
 	if t.y_i+default_Margin+float64(len(lines))*unitSize > pageHeight*2 {
+		t.problems = append(t.problems, layoutProblem{0, 0, issueTooLong, text})
 		return
 	}
 
@@ -825,6 +854,7 @@ func (t *Table) Render(outLine bool) {
 
 		}
 	}()
+	t.outLine = outLine
 	fmt.Printf("[Render] initialpageNum=%d, pageNum=%d\n", t.initialpageNum, t.pageNum)
 	for i := t.initialpageNum; i <= t.pageNum; i++ {
 		if i > t.pdf.PageNo() {
@@ -837,7 +867,9 @@ func (t *Table) Render(outLine bool) {
 				fmt.Printf("[Render] Rect(F): page=%d x=%.2f y=%.2f w=%.2f h=%.2f LineWidth=%.2f\n", rect.pageNum, rect.x, rect.y, rect.w, rect.h, rect.LineWidth)
 				t.pdf.SetXY(rect.x, rect.y)
 				t.pdf.SetLineWidth(rect.LineWidth)
-				t.pdf.Rect(rect.x, rect.y, rect.w, rect.h, rect.style)
+				t.withFillColor(rect.fillColor, func() {
+					t.pdf.Rect(rect.x, rect.y, rect.w, rect.h, rect.style)
+				})
 			}
 		}
 		for _, cell := range t.Cells {
@@ -846,7 +878,17 @@ func (t *Table) Render(outLine bool) {
 				t.pdf.SetXY(cell.x, cell.y)
 				t.pdf.SetFont(t.font, "", cell.fontSize)
 				t.pdf.SetLineWidth(cell.LineWidth)
-				t.pdf.CellFormat(cell.w, cell.h, cell.text, cell.border, 0, cell.align, cell.fill, 0, cell.link)
+				if len(cell.runs) > 0 {
+					// 枠と塗りつぶしだけ描いてから、書式ごとに文字を描く
+					t.withFillColor(cell.fillColor, func() {
+						t.pdf.CellFormat(cell.w, cell.h, "", cell.border, 0, cell.align, cell.fill, 0, cell.link)
+					})
+					t.drawRuns(cell)
+					continue
+				}
+				t.withFillColor(cell.fillColor, func() {
+					t.pdf.CellFormat(cell.w, cell.h, cell.text, cell.border, 0, cell.align, cell.fill, 0, cell.link)
+				})
 			}
 		}
 		for _, text := range t.Texts {
@@ -881,11 +923,25 @@ func (t *Table) Render(outLine bool) {
 	}
 }
 
+// withFillColor は塗りつぶしの色を一時的に color（"RRGGBB"）にして描画する
+func (t *Table) withFillColor(color string, draw func()) {
+	r, g, b, ok := parseHexColor(color)
+	if !ok {
+		draw()
+		return
+	}
+	r0, g0, b0 := t.pdf.GetFillColor()
+	t.pdf.SetFillColor(r, g, b)
+	draw()
+	t.pdf.SetFillColor(r0, g0, b0)
+}
+
 func CalcTextHeight(pdf *gofpdf.Fpdf, text string, width float64, lineHeight float64) float64 {
 	lines := pdf.SplitLines([]byte(text), width)
 	return float64(len(lines)) * lineHeight
 }
 
+// 1文字も収まらない幅でも、分割が進むよう少なくとも1文字を返す
 func GetMaxChars(pdf *gofpdf.Fpdf, runes []rune, start int, width float64, fontSize float64) int {
 	accumWidth := 0.0
 	for i := start; i < len(runes); i++ {
@@ -893,7 +949,7 @@ func GetMaxChars(pdf *gofpdf.Fpdf, runes []rune, start int, width float64, fontS
 		pdf.SetFontSize(fontSize) // フォントサイズを設定
 		w := pdf.GetStringWidth(ch)
 		if accumWidth+w > width-1 { // 0.5は余白調整
-			return i - start
+			return max(i-start, 1)
 		}
 		accumWidth += w
 	}
@@ -944,298 +1000,110 @@ func SplitByMaxChars(pdf *gofpdf.Fpdf, text string, width float64, fontSize floa
 	return lines
 }
 
-// xlsxファイルをPDFディレクトリに保存し、A1:AD48をgofpdfでPDF出力
-func (a *App) SaveXLSXsToPDFDir(files []FileData) error {
+// postingFileName は出力ファイル名を返す。入力ファイル名（source）を含め、
+// シートが1つの場合は職種・勤務地を、複数の場合は日付を付け加える
+func postingFileName(source string, postings [][][]string, ext string) string {
+	if len(postings) == 1 {
+		p := NewJobPosting(postings[0])
+		return joinFileName("求人票", sourceBaseName(source), p.JobTitle, p.Location) + ext
+	}
+	return joinFileName("求人票", sourceBaseName(source), time.Now().Format("20060102")) + ext
+}
 
-	defer func() {
-		if r := recover(); r != nil {
-			log.Println("Recovered from panic:", r)
+// sourceBaseName は入力ファイル名（ZIP内のパスを含む）から拡張子を除いたファイル名を返す
+func sourceBaseName(source string) string {
+	base := path.Base(filepath.ToSlash(source))
+	return strings.TrimSuffix(base, path.Ext(base))
+}
+
+// safeFileName はセルの値などをファイル名に使えるようにする（区切り文字・使えない記号・改行は「_」にする）
+func safeFileName(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsSpace(r):
+			return ' '
+		case strings.ContainsRune(`/\:*?"<>|`, r), unicode.IsControl(r):
+			return '_'
 		}
-	}()
+		return r
+	}, s)
+	s = strings.Join(strings.Fields(s), " ")
+	// Windows では末尾のピリオドを付けられず、「.」「..」は親フォルダなどを指す
+	return strings.TrimRight(s, ". ")
+}
 
-	// フォントファイルの読み込み
-	fontFile, fontPath, err := a.loadFont()
-	if err != nil {
-		return err
+// joinFileName はファイル名に使えるようにした parts を「_」でつなぐ（空の部分は除く）
+func joinFileName(parts ...string) string {
+	var names []string
+	for _, p := range parts {
+		if p = safeFileName(p); p != "" {
+			names = append(names, p)
+		}
 	}
-	defer fontFile.Close()
-	defer os.Remove(fontPath)
+	return strings.Join(names, "_")
+}
 
-	Dpath, err := GetDownloadsPath()
-	if err != nil {
-		return fmt.Errorf("ダウンロードパスの取得に失敗: %w", err)
+// uniquePath は既にあるファイルを上書きしないよう、必要なら「_2」などを付けたパスを返す
+func uniquePath(p string) string {
+	ext := filepath.Ext(p)
+	base := strings.TrimSuffix(p, ext)
+	for n := 2; ; n++ {
+		if _, err := os.Lstat(p); os.IsNotExist(err) {
+			return p
+		}
+		p = fmt.Sprintf("%s_%d%s", base, n, ext)
 	}
+}
 
-	for _, f := range files {
+// newPostingPDF は埋め込みフォントを登録したA4のPDFを作る
+func newPostingPDF(fontPath string) *gofpdf.Fpdf {
+	pdf := gofpdf.New("P", "mm", "A4", os.TempDir())
+	baseName := filepath.Base(fontPath)
+	pdf.AddUTF8Font("IPA", "", baseName)
+	pdf.SetAutoPageBreak(false, 0.0) // 自動改ページを無効化
+	pdf.AddPage()
+	return pdf
+}
 
-		fx, _, err := a.loadCSV(f)
-		if err != nil {
-			return fmt.Errorf("CSVファイルの読み込みに失敗: %w", err)
+// buildPostingPDF はシートごとの求人票を1つのPDFに描画し、各シートの描画結果も返す。
+// シートの書式を読み込んだシートは、テンプレートのレイアウトではなくその書式どおりに描画する。
+// imageSlot はテンプレートのレイアウトで元の位置に置けない画像の配置先
+func buildPostingPDF(fontPath string, loaded loadedPostings, imageSlot string) (*gofpdf.Fpdf, []postingLayout) {
+	pdf := newPostingPDF(fontPath)
+	var layouts []postingLayout
+	for index, tableData := range loaded.postings {
+		if index != 0 {
+			pdf.AddPage()
 		}
-		defer fx.Close()
-
-		pdfPath := filepath.Join(Dpath, "求人票_"+time.Now().Format("20060102")+".pdf")
-
-		// PDF生成
-		pdf := gofpdf.New("P", "mm", "A4", os.TempDir())
-		baseName := filepath.Base(fontPath)
-		pdf.AddUTF8Font("IPA", "", baseName)
-		pdf.SetAutoPageBreak(false, 0.0) // 自動改ページを無効化
-		pdf.AddPage()
-
-		sheets := fx.GetSheetList()
-		if len(sheets) == 0 {
-			return fmt.Errorf("%s: シートがありません", f.Name)
+		var pictures []sheetPicture
+		if index < len(loaded.pictures) {
+			pictures = loaded.pictures[index]
 		}
-
-		for index, sheet := range sheets {
-			// CSVファイルの読み込み
-			tableData, err := a.loadData(sheet, fx)
-			if err != nil {
-				return err
-			}
-
-			if index != 0 {
-				pdf.AddPage()
-			}
-			// MARGIN CONFIG
-			marginSide := 30.0
-			marginTop := 13.0
-
-			// TITLE
-			pdf.SetFont("IPA", "", 20)
-			title := "求人票"
-			titleW := pdf.GetStringWidth(title)
-			_, titleH := pdf.GetFontSize()
-			pageW, _ := pdf.GetPageSize()
-			x := (pageW - titleW) / 2
-			y := marginTop
-			pdf.SetXY(x, y)
-			pdf.CellFormat(titleW, titleH, title, "", 0, "L", false, 0, "")
-
-			// COMPANY NAME
-			pdf.SetFontSize(7.0)
-			company := "株式会社アーリー・バード・エージェント"
-			companyW := pdf.GetStringWidth(company)
-			_, companyH := pdf.GetFontSize() // サイズ取得は不要だが、GetFontSize()を呼び出しておく
-			x = pageW - companyW - marginSide
-			y = marginTop
-			pdf.SetXY(x, y)
-			pdf.CellFormat(companyW, companyH, company, "", 0, "L", false, 0, "")
-
-			// TABLE A
-			pdf.SetFillColor(153, 204, 255)
-			w := 5.0
-			offsetH := 2.0
-			pdf.SetXY(marginSide, marginTop+titleH)
-			pdf.SetFontSize(6.0)
-
-			rowNum := 1
-			rowH := 2.5
-			ft := 6.0 // フォントサイズ
-			dh := 4.5 // デフォルトのセル高さ
-			tableID := NewTable(pdf, marginSide+w, marginTop+titleH+offsetH-rowH, pageW-marginSide, marginTop+titleH+offsetH, 9, rowNum, "IPA", ft, dh, "1")
-			tableID.SetCell(7, 0, 8, 1, tableData[2][24], "C", true, 5.0, "", 0.3, rowH)
-			tableID.SetCell(8, 0, 9, 1, tableData[2][27], "C", false, 5.0, "", 0.3, rowH)
-			tableID.Render(false)
-			fmt.Print("[Render] completed tableID\n")
-			rowNum = 8
-			rowH = 4.0
-
-			table := NewTable(pdf, marginSide+w, marginTop+titleH+offsetH, pageW-marginSide, marginTop+titleH+offsetH+rowH, 9, rowNum, "IPA", ft, dh, "1")
-
-			lw := 0.1 // セルの枠線の太さ
-			table.SetCell(1, 0, 6, 1, tableData[3][2], "L", false, 5.0, "", lw, 2.5)
-			table.SetCell(1, 1, 6, 2, tableData[4][2], "L", false, 10.0, "", lw, 7.0)
-			table.SetCell(0, 0, 1, 2, tableData[3][1], "C", true, -1.0, "", lw, rowH)
-
-			table.SetCell(6, 0, 7, 2, tableData[3][21], "C", true, -1.0, "", lw, rowH)
-			table.SetMultiRowCell(7, 0, 9, 2, tableData[3][24], "L", false, -1.0, false)
-
-			table.SetCell(0, 2, 1, 3, tableData[5][1], "C", true, -1.0, "", lw, rowH)
-			table.SetCell(1, 2, 3, 3, tableData[5][2], "L", false, -1.0, "", lw, rowH)
-			table.SetCell(3, 2, 4, 3, tableData[5][10], "C", true, -1.0, "", lw, rowH)
-			table.SetCell(4, 2, 6, 3, tableData[5][13], "L", false, -1.0, "", lw, rowH)
-			table.SetCell(6, 2, 7, 3, tableData[5][21], "C", true, -1.0, "", lw, rowH)
-			table.SetCell(7, 2, 9, 3, tableData[5][24], "L", false, -1.0, "", lw, rowH)
-
-			table.SetCell(0, 3, 1, 4, tableData[6][1], "C", true, -1.0, "", lw, rowH)
-			table.SetCell(1, 3, 3, 4, tableData[6][2], "L", false, -1.0, "", lw, rowH)
-			table.SetCell(3, 3, 4, 4, tableData[6][10], "C", true, -1.0, "", lw, rowH)
-			table.SetCell(4, 3, 9, 4, tableData[6][13], "L", false, -1.0, "", lw, rowH)
-
-			table.SetCell(0, 4, 1, 5, tableData[7][1], "C", true, -1.0, "", lw, rowH)
-			table.SetCell(1, 4, 9, 5, tableData[7][2], "L", false, -1.0, "", lw, rowH)
-
-			table.SetMultiRowCell(1, 5, 9, 6, tableData[8][2], "L", false, -1.0, false)
-			table.SetCell(0, 5, 1, 6, tableData[8][1], "C", true, -1.0, "", lw, rowH)
-
-			table.SetMultiRowCell(1, 6, 9, 7, tableData[9][2], "L", false, -1.0, true)
-			table.SetCell(0, 6, 1, 7, tableData[9][1], "C", true, -1.0, "", lw, rowH)
-
-			table.SetMultiRowCell(1, 7, 9, 8, tableData[10][2], "L", false, -1.0, true)
-			table.SetCell(0, 7, 1, 8, tableData[10][1], "C", true, -1.0, "", lw, rowH)
-
-			table.SetTitle(tableData[3][0])
-			table.Render(true)
-
-			// TABLE B
-			pdf.SetLineWidth(0.1)
-			currentH := table.Ys[len(table.Ys)-1] + offsetH
-			rowNum = 10
-			rowH = 4.5
-
-			table2 := NewTable(pdf, marginSide+w, currentH, pageW-marginSide, currentH+rowH, 9, rowNum, "IPA", ft, dh, "1")
-
-			table2.SetCell(0, 0, 1, 1, tableData[12][1], "C", true, -1.0, "", lw, rowH)
-			table2.SetCell(1, 0, 9, 1, tableData[12][2], "L", false, -1.0, "", lw, rowH)
-
-			table2.SetMultiRowCell(1, 1, 9, 2, tableData[13][2], "L", false, -1.0, true)
-			table2.SetCell(0, 1, 1, 2, tableData[13][1], "C", true, -1.0, "", lw, rowH)
-
-			table2.SetCell(0, 2, 1, 4, tableData[14][1], "C", true, -1.0, "", lw, rowH*2)
-			table2.SetCell(1, 2, 3, 4, tableData[14][2], "L", false, -1.0, "", lw, rowH*2)
-			table2.SetCell(3, 2, 4, 3, tableData[14][10], "C", true, -1.0, "", lw, rowH)
-			table2.SetCell(4, 2, 6, 3, tableData[14][13], "L", false, -1.0, "", lw, rowH)
-			table2.SetCell(6, 2, 7, 3, tableData[14][21], "C", true, -1.0, "", lw, rowH)
-			table2.SetCell(7, 2, 9, 3, tableData[14][24], "L", false, -1.0, "", lw, rowH)
-
-			table2.SetCell(3, 3, 4, 4, tableData[15][10], "C", true, -1.0, "", lw, rowH)
-			table2.SetCell(4, 3, 9, 4, tableData[15][13], "L", false, -1.0, "", lw, rowH)
-
-			table2.SetMultiRowCell(1, 4, 9, 5, tableData[16][2], "L", false, -1.0, false)
-			table2.SetCell(0, 4, 1, 5, tableData[16][1], "C", true, -1.0, "", lw, rowH)
-
-			table2.SetMultiRowCell(1, 5, 9, 6, tableData[17][2], "L", false, -1.0, false)
-			table2.SetCell(0, 5, 1, 6, tableData[17][1], "C", true, -1.0, "", lw, rowH)
-
-			table2.SetMultiRowCell(1, 6, 9, 7, tableData[18][2], "L", false, -1.0, false)
-			table2.SetCell(0, 6, 1, 7, tableData[18][1], "C", true, -1.0, "", lw, rowH)
-
-			table2.SetCell(0, 7, 1, 8, tableData[19][1], "C", true, -1.0, "", lw, rowH)
-			table2.SetCell(1, 7, 3, 8, tableData[19][2], "L", false, -1.0, "", lw, rowH)
-			table2.SetCell(3, 7, 4, 8, tableData[19][10], "C", true, -1.0, "", lw, rowH)
-			table2.SetCell(4, 7, 9, 8, tableData[19][13], "L", false, -1.0, "", lw, rowH)
-
-			table2.SetCell(0, 8, 1, 9, tableData[20][1], "C", true, -1.0, "", lw, rowH)
-			table2.SetCell(1, 8, 3, 9, tableData[20][2], "L", false, -1.0, "", lw, rowH)
-			table2.SetCell(3, 8, 4, 9, tableData[20][10], "C", true, -1.0, "", lw, rowH)
-			table2.SetCell(4, 8, 6, 9, tableData[20][13], "L", false, -1.0, "", lw, rowH)
-			table2.SetCell(6, 8, 7, 9, tableData[20][21], "C", true, -1.0, "", lw, rowH)
-			table2.SetCell(7, 8, 9, 9, tableData[20][24], "L", false, -1.0, "", lw, rowH)
-
-			table2.SetMultiRowCell(1, 9, 9, 10, tableData[21][2], "L", false, -1.0, true)
-			table2.SetCell(0, 9, 1, 10, tableData[21][1], "C", true, -1.0, "", lw, rowH)
-
-			table2.SetTitle(tableData[12][0])
-			table2.Render(true)
-
-			// TABLE C
-			pdf.SetLineWidth(0.1)
-			currentH = table2.Ys[len(table2.Ys)-1] + offsetH
-			rowNum = 2
-
-			table3 := NewTable(pdf, marginSide+w, currentH, pageW-marginSide, currentH+rowH, 9, rowNum, "IPA", ft, dh, "1")
-
-			table3.SetCell(0, 0, 1, 1, tableData[23][1], "C", true, -1.0, "", lw, rowH)
-			table3.SetCell(1, 0, 3, 1, tableData[23][2], "L", false, -1.0, "", lw, rowH)
-			table3.SetCell(3, 0, 4, 1, tableData[23][10], "C", true, -1.0, "", lw, rowH)
-			table3.SetCell(4, 0, 9, 1, tableData[23][13], "L", false, -1.0, "", lw, rowH)
-
-			table3.SetMultiRowCell(1, 1, 9, 2, tableData[24][2], "L", false, -1.0, true)
-			table3.SetMultiRowCell(0, 1, 1, 2, tableData[24][1], "C", true, -1.0, true)
-
-			table3.SetTitle(tableData[23][0])
-			table3.Render(true)
-
-			// TABLE D
-			pdf.SetLineWidth(0.1)
-			currentH = table3.Ys[len(table3.Ys)-1] + offsetH
-			rowNum = 6
-
-			table4 := NewTable(pdf, marginSide+w, currentH, pageW-marginSide, currentH+rowH, 9, rowNum, "IPA", ft, dh, "1")
-
-			table4.SetCell(0, 0, 1, 1, tableData[26][1], "C", true, -1.0, "", lw, rowH)
-			table4.SetCell(1, 0, 3, 1, tableData[26][2], "L", false, -1.0, "", lw, rowH)
-			table4.SetCell(3, 0, 4, 1, tableData[26][10], "C", true, -1.0, "", lw, rowH)
-			table4.SetCell(4, 0, 6, 1, tableData[26][13], "L", false, -1.0, "", lw, rowH)
-			table4.SetCell(6, 0, 7, 1, tableData[26][21], "C", true, -1.0, "", lw, rowH)
-			table4.SetCell(7, 0, 9, 1, tableData[26][24], "L", false, -1.0, "", lw, rowH)
-
-			table4.SetCell(0, 1, 1, 2, tableData[27][1], "C", true, -1.0, "", lw, rowH)
-			table4.SetCell(1, 1, 3, 2, tableData[27][2], "L", false, -1.0, "", lw, rowH)
-			table4.SetCell(3, 1, 4, 2, tableData[27][10], "C", true, -1.0, "", lw, rowH)
-			table4.SetCell(4, 1, 6, 2, tableData[27][13], "L", false, -1.0, "", lw, rowH)
-			table4.SetCell(6, 1, 7, 2, tableData[27][21], "C", true, -1.0, "", lw, rowH)
-			table4.SetCell(7, 1, 9, 2, tableData[27][24], "L", false, -1.0, "", lw, rowH)
-
-			table4.SetMultiRowCell(1, 2, 9, 3, tableData[28][2], "L", false, -1.0, true)
-			table4.SetCell(0, 2, 1, 3, tableData[28][1], "C", true, -1.0, "", lw, rowH)
-
-			table4.SetMultiRowCell(1, 3, 9, 4, tableData[29][2], "L", false, -1.0, true)
-			table4.SetCell(0, 3, 1, 4, tableData[29][1], "C", true, -1.0, "", lw, rowH)
-
-			table4.SetMultiRowCell(1, 4, 9, 5, tableData[30][2], "L", false, -1.0, true)
-			table4.SetCell(0, 4, 1, 5, tableData[30][1], "C", true, -1.0, "", lw, rowH)
-
-			table4.SetMultiRowCell(1, 5, 6, 6, tableData[31][2], "L", false, -1.0, true)
-			table4.SetCell(0, 5, 1, 6, tableData[31][1], "C", true, -1.0, "", lw, rowH)
-			table4.SetCell(6, 5, 7, 6, tableData[31][21], "C", true, -1.0, "", lw, rowH)
-			table4.SetCell(7, 5, 9, 6, tableData[31][24], "L", false, -1.0, "", lw, rowH)
-
-			table4.SetTitle(tableData[26][0])
-			table4.Render(true)
-
-			// TABLE E
-			pdf.SetLineWidth(0.1)
-			currentH = table4.Ys[len(table4.Ys)-1] + offsetH
-			rowNum = 3
-
-			table5 := NewTable(pdf, marginSide+w, currentH, pageW-marginSide, currentH+rowH, 9, rowNum, "IPA", ft, dh, "1")
-
-			table5.SetMultiRowCell(1, 0, 6, 1, tableData[33][2], "L", false, -1.0, false)
-			table5.SetCell(0, 0, 1, 1, tableData[33][1], "C", true, -1.0, "", lw, rowH)
-			table5.SetCell(6, 0, 7, 1, tableData[33][21], "C", true, -1.0, "", lw, rowH)
-			table5.SetCell(7, 0, 9, 1, tableData[33][24], "L", false, -1.0, "", lw, rowH)
-
-			table5.SetMultiRowCell(1, 1, 9, 2, tableData[34][2], "L", false, -1.0, false)
-			table5.SetCell(0, 1, 1, 2, tableData[34][1], "C", true, -1.0, "", lw, rowH)
-
-			table5.SetMultiRowCell(1, 2, 9, 3, tableData[35][2], "L", false, -1.0, false)
-			table5.SetCell(0, 2, 1, 3, tableData[35][1], "C", true, -1.0, "", lw, rowH)
-
-			table5.SetTitle(tableData[33][0])
-			table5.Render(true)
-
-			// TABLE F
-			currentH = table5.Ys[len(table5.Ys)-1] + offsetH
-			rowNum = 1
-
-			table6 := NewTable(pdf, marginSide+w, currentH, pageW-marginSide, currentH+rowH, 9, rowNum, "IPA", ft, dh, "1")
-
-			table6.SetMultiRowCell(1, 0, 9, 1, tableData[37][2], "L", false, -1.0, false)
-			table6.SetCellWithTitle(0, 0, 1, 1, tableData[37][0], "C", true, -1.0)
-
-			table6.Render(false)
-
-			// // Appendix
-			currentH = table6.Ys[len(table6.Ys)-1] + offsetH
-
-			table7 := NewAppendix(pdf, marginSide, pageW-marginSide, currentH, "IPA", ft, dh, "0")
-			table7.SetAppendix(tableData[41][0], "L", false, -1.0, true)
-
-			table7.Render(false)
-
-			if index == 0 && len(sheets) == 1 {
-				pdfPath = filepath.Join(Dpath, "求人票_"+tableData[4][2]+"_"+tableData[12][2]+".pdf")
-			}
+		if index < len(loaded.layouts) && loaded.layouts[index] != nil {
+			layouts = append(layouts, renderSheetLayout(pdf, loaded.layouts[index], pictures))
+			continue
 		}
-
-		err = pdf.OutputFileAndClose(pdfPath)
-		if err != nil {
-			return fmt.Errorf("%s のPDF出力に失敗: %w", f.Name, err)
+		var rich map[CellRef][]TextRun
+		if index < len(loaded.richText) {
+			rich = loaded.richText[index]
 		}
-		fmt.Printf("PDFファイルを保存しました: %s\n", pdfPath)
+		layout := renderPosting(pdf, tableData, rich)
+		placeTemplatePictures(pdf, &layout, pictures, imageSlot)
+		layouts = append(layouts, layout)
+	}
+	return pdf, layouts
+}
+
+// xlsxファイルをPDFディレクトリに保存し、gofpdfでPDF出力。
+// 読み取り範囲は印刷範囲、名前付き範囲「求人票」、使用範囲の順に決める（A1:AD48より狭くはしない）。
+// 入力エラーで出力しなかったシートがある場合はエラーにする
+func (a *App) SaveXLSXsToPDFDir(files []FileData) error {
+	result, err := a.ConvertXLSXs(files, ConvertOptions{PDF: true})
+	if err != nil {
+		return err
+	}
+	if len(result.Blocked) > 0 {
+		return fmt.Errorf("入力エラーがあるため出力しなかったシートがあります: %s", strings.Join(result.Blocked, "、"))
 	}
 	return nil
 }
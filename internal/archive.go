@@ -0,0 +1,179 @@
+package internal
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// ZIPファイルを展開するときの上限
+const (
+	maxArchiveEntries   = 1000      // ファイル数
+	maxArchiveEntrySize = 50 << 20  // 1ファイルの展開後のサイズ
+	maxArchiveTotalSize = 500 << 20 // 展開後の合計サイズ
+	archiveExt          = ".zip"
+)
+
+// 変換できる表計算ファイルの拡張子
+var spreadsheetExts = map[string]bool{
+	".xlsx": true,
+	".xlsm": true,
+	".xls":  true,
+	".ods":  true,
+	".csv":  true,
+	".tsv":  true,
+}
+
+// isArchive はZIPファイルかどうかを拡張子で判定する
+func isArchive(name string) bool {
+	return strings.ToLower(filepath.Ext(name)) == archiveExt
+}
+
+// archiveEntryName はZIP内のファイル名を安全な相対パスにする。
+// 絶対パスや親ディレクトリを指すもの（zip-slip）は ok = false を返す
+func archiveEntryName(zf *zip.File) (string, bool) {
+	name := zf.Name
+	// 日本語版Windowsで作成したZIPはファイル名がShift_JISのことがある
+	if zf.NonUTF8 && !utf8.ValidString(name) {
+		if decoded, _, err := decodeText([]byte(name)); err == nil {
+			name = decoded
+		}
+	}
+	name = strings.ReplaceAll(name, "\\", "/")
+	if strings.HasPrefix(name, "/") || (len(name) >= 2 && name[1] == ':') {
+		return "", false
+	}
+	name = path.Clean(name)
+	if name == "." || name == ".." || strings.HasPrefix(name, "../") {
+		return "", false
+	}
+	return name, true
+}
+
+// skipArchiveEntry はOSが自動で作るファイル（__MACOSX・隠しファイルなど）かどうかを返す
+func skipArchiveEntry(name string) bool {
+	for _, part := range strings.Split(name, "/") {
+		if part == "__MACOSX" || strings.HasPrefix(part, ".") || strings.HasPrefix(part, "~$") {
+			return true
+		}
+	}
+	return strings.EqualFold(path.Base(name), "Thumbs.db") || strings.EqualFold(path.Base(name), "desktop.ini")
+}
+
+// expandArchive はZIPファイルの中の表計算ファイルを取り出す。
+// 取り出したファイル名は「ZIPファイル名（拡張子なし）/ZIP内のパス」で、出力先のフォルダ構成に使う
+func expandArchive(f FileData) ([]FileData, []string, error) {
+	data, err := decodeFileData(f)
+	if err != nil {
+		return nil, nil, err
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s のZIP読込に失敗: %w", f.Name, err)
+	}
+	if len(zr.File) > maxArchiveEntries {
+		return nil, nil, fmt.Errorf("%s: ファイル数が多すぎます（上限 %d）", f.Name, maxArchiveEntries)
+	}
+
+	base := strings.TrimSuffix(f.Name, filepath.Ext(f.Name))
+	var files []FileData
+	var warnings []string
+	var total int64
+	for _, zf := range zr.File {
+		if zf.FileInfo().IsDir() {
+			continue
+		}
+		name, ok := archiveEntryName(zf)
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("%s: 不正なパス %s を含むため展開しません", f.Name, zf.Name))
+			continue
+		}
+		if skipArchiveEntry(name) {
+			continue
+		}
+		if !spreadsheetExts[strings.ToLower(path.Ext(name))] {
+			warnings = append(warnings, fmt.Sprintf("%s: %s は対応していないファイルのため変換しません", f.Name, name))
+			continue
+		}
+		if zf.UncompressedSize64 > maxArchiveEntrySize {
+			return nil, nil, fmt.Errorf("%s: %s が大きすぎます（上限 %dMB）", f.Name, name, maxArchiveEntrySize>>20)
+		}
+
+		b, err := readArchiveEntry(zf)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %s の展開に失敗: %w", f.Name, name, err)
+		}
+		total += int64(len(b))
+		if total > maxArchiveTotalSize {
+			return nil, nil, fmt.Errorf("%s: 展開後のサイズが大きすぎます（上限 %dMB）", f.Name, maxArchiveTotalSize>>20)
+		}
+		files = append(files, FileData{
+			Name:     path.Join(filepath.ToSlash(base), name),
+			Data:     base64.StdEncoding.EncodeToString(b),
+			Password: f.Password,
+		})
+	}
+	if len(files) == 0 {
+		return nil, warnings, fmt.Errorf("%s: 変換できるファイルがありません", f.Name)
+	}
+	return files, warnings, nil
+}
+
+// readArchiveEntry はZIP内のファイルを読む。ヘッダーのサイズが偽装されていても上限以上は読まない
+func readArchiveEntry(zf *zip.File) ([]byte, error) {
+	rc, err := zf.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	b, err := io.ReadAll(io.LimitReader(rc, maxArchiveEntrySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > maxArchiveEntrySize {
+		return nil, fmt.Errorf("サイズが大きすぎます（上限 %dMB）", maxArchiveEntrySize>>20)
+	}
+	return b, nil
+}
+
+// expandArchives は入力ファイルのうちZIPファイルを中の表計算ファイルに置き換える
+func expandArchives(files []FileData) ([]FileData, []string, error) {
+	var out []FileData
+	var warnings []string
+	for _, f := range files {
+		if !isArchive(f.Name) {
+			out = append(out, f)
+			continue
+		}
+		expanded, ws, err := expandArchive(f)
+		warnings = append(warnings, ws...)
+		if err != nil {
+			return nil, warnings, err
+		}
+		out = append(out, expanded...)
+	}
+	return out, warnings, nil
+}
+
+// outputDir は入力ファイル名のフォルダ部分（ZIP内のフォルダ構成）に対応する出力先を作る
+func outputDir(root, name string) (string, error) {
+	dir := filepath.Dir(filepath.FromSlash(name))
+	if dir == "." {
+		return root, nil
+	}
+	if !filepath.IsLocal(dir) {
+		return "", fmt.Errorf("%s: 出力先のフォルダが不正です", name)
+	}
+	dir = filepath.Join(root, dir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("出力フォルダの作成に失敗: %w", err)
+	}
+	return dir, nil
+}
@@ -0,0 +1,235 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// 入力用テンプレートのシート名とファイル名
+const (
+	blankTemplateSheet = "求人票"
+	blankTemplateName  = "求人票テンプレート.xlsx"
+)
+
+// 入力用テンプレートの書式
+const (
+	blankLabelFill = "D9D9D9" // ラベル・タイトルの背景
+	blankInputFill = "FFF2CC" // 入力するセルの背景
+	blankRowHeight = 20.0     // 行の高さ（pt）
+	blankTallRow   = 60.0     // 複数行の項目の行の高さ（pt）
+)
+
+// fieldChoices: 選択肢から選ぶ項目（JSON-LD・求人フィードで読み取れる表記にする）
+var fieldChoices = map[string][]string{
+	"employmentType": {"正社員", "契約社員", "パート・アルバイト", "派遣社員", "業務委託", "インターン"},
+	"salaryType":     {"月給", "時給", "日給", "年俸"},
+	"listing":        {"非上場", "東証プライム", "東証スタンダード", "東証グロース", "その他"},
+}
+
+// fieldHints: 入力するセルのコメントにする入力のヒント
+var fieldHints = map[string]string{
+	"id":               "社内の求人番号",
+	"jobTitleKana":     "職種のふりがな（ひらがな）",
+	"jobTitle":         "求人サイトに表示する職種名",
+	"employmentType":   "一覧から選択してください",
+	"openings":         "例: 2名",
+	"description":      "改行して複数行で入力できます",
+	"location":         "都道府県から入力してください（例: 東京都港区芝公園4-2-8）",
+	"salaryType":       "一覧から選択してください",
+	"salaryMin":        "数値で入力してください（例: 250000）",
+	"salaryMax":        "数値で入力してください。給与下限以上にしてください",
+	"hours":            "例: 9:00〜18:00（休憩60分）",
+	"deadline":         "日付で入力してください（例: 2024/10/31）",
+	"startDate":        "日付または「応相談」",
+	"selectionProcess": "例: 書類選考 → 面接2回 → 内定",
+	"website":          "https:// から入力してください",
+	"listing":          "一覧から選択してください",
+	"notice":           "求人票の末尾に小さく表示する注記",
+}
+
+// fieldNumFmts: 入力するセルの表示形式
+var fieldNumFmts = map[string]string{
+	"salaryMin": "#,##0",
+	"salaryMax": "#,##0",
+	"deadline":  "yyyy/m/d",
+	"startDate": "yyyy/m/d",
+}
+
+// tallFields: 複数行で入力する項目
+var tallFields = map[string]bool{
+	"description": true, "requirements": true, "preferred": true, "benefits": true,
+	"selectionProcess": true, "business": true, "companyFeatures": true,
+	"workEnvironment": true, "careerPath": true, "message": true, "notes": true, "notice": true,
+}
+
+// blankCellEnd は行 row の col から始まるセルを結合する右端の列を返す（同じ行の次のラベル・値の手前まで）
+func blankCellEnd(grid [][]string, values map[CellRef]bool, row, col int) int {
+	for c := col + 1; c < gridCols; c++ {
+		if grid[row][c] != "" || values[at(row, c)] {
+			return c - 1
+		}
+	}
+	return gridCols - 1
+}
+
+// WriteBlankTemplate は現行の項目マッピングから入力用のxlsxテンプレートを書き出す。
+// 版のセルに現行の版を書くため、書き出したテンプレートは必ず現行の版として読み込める
+func WriteBlankTemplate(w io.Writer) error {
+	fx := excelize.NewFile()
+	defer fx.Close()
+	if err := fx.SetSheetName("Sheet1", blankTemplateSheet); err != nil {
+		return err
+	}
+	sheet := blankTemplateSheet
+
+	grid := BlankGrid()
+	values := map[CellRef]bool{}
+	for _, f := range postingFields {
+		values[f.Ref] = true
+	}
+
+	if err := fx.SetColWidth(sheet, "A", "A", 10); err != nil {
+		return err
+	}
+	if err := fx.SetColWidth(sheet, "B", "B", 12); err != nil {
+		return err
+	}
+	if err := fx.SetColWidth(sheet, "C", "AD", 4.5); err != nil {
+		return err
+	}
+	for r := 1; r <= gridRows; r++ {
+		if err := fx.SetRowHeight(sheet, r, blankRowHeight); err != nil {
+			return err
+		}
+	}
+
+	border := []excelize.Border{
+		{Type: "left", Color: "808080", Style: 1},
+		{Type: "top", Color: "808080", Style: 1},
+		{Type: "right", Color: "808080", Style: 1},
+		{Type: "bottom", Color: "808080", Style: 1},
+	}
+	labelStyle, err := fx.NewStyle(&excelize.Style{
+		Font:      &excelize.Font{Bold: true},
+		Fill:      excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{blankLabelFill}},
+		Border:    border,
+		Alignment: &excelize.Alignment{Vertical: "center", WrapText: true},
+	})
+	if err != nil {
+		return err
+	}
+
+	// ラベルとタイトル
+	for r, row := range grid {
+		for c, label := range row {
+			if label == "" {
+				continue
+			}
+			start, _ := excelize.CoordinatesToCellName(c+1, r+1)
+			end, _ := excelize.CoordinatesToCellName(blankCellEnd(grid, values, r, c)+1, r+1)
+			if err := fx.SetCellValue(sheet, start, label); err != nil {
+				return err
+			}
+			if start != end {
+				if err := fx.MergeCell(sheet, start, end); err != nil {
+					return err
+				}
+			}
+			if err := fx.SetCellStyle(sheet, start, end, labelStyle); err != nil {
+				return err
+			}
+		}
+	}
+
+	// 版のセル
+	versionCell, _ := excelize.CoordinatesToCellName(templateVersionCell.Col+1, templateVersionCell.Row+1)
+	if err := fx.SetCellValue(sheet, versionCell, templateVersions[0].versionCellText()); err != nil {
+		return err
+	}
+
+	// 入力するセル
+	for _, f := range postingFields {
+		start, _ := excelize.CoordinatesToCellName(f.Ref.Col+1, f.Ref.Row+1)
+		end, _ := excelize.CoordinatesToCellName(blankCellEnd(grid, values, f.Ref.Row, f.Ref.Col)+1, f.Ref.Row+1)
+		if start != end {
+			if err := fx.MergeCell(sheet, start, end); err != nil {
+				return err
+			}
+		}
+		style := &excelize.Style{
+			Fill:      excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{blankInputFill}},
+			Border:    border,
+			Alignment: &excelize.Alignment{Vertical: "top", WrapText: true},
+		}
+		if code, ok := fieldNumFmts[f.Key]; ok {
+			style.CustomNumFmt = &code
+		}
+		id, err := fx.NewStyle(style)
+		if err != nil {
+			return err
+		}
+		if err := fx.SetCellStyle(sheet, start, end, id); err != nil {
+			return err
+		}
+		if tallFields[f.Key] {
+			if err := fx.SetRowHeight(sheet, f.Ref.Row+1, blankTallRow); err != nil {
+				return err
+			}
+		}
+		if choices, ok := fieldChoices[f.Key]; ok {
+			dv := excelize.NewDataValidation(true)
+			dv.SetSqref(start + ":" + end)
+			if err := dv.SetDropList(choices); err != nil {
+				return err
+			}
+			// 一覧にない表記も入力できるようにする
+			dv.SetError(excelize.DataValidationErrorStyleWarning, f.Label, "一覧にない値です。このまま入力しますか？")
+			if err := fx.AddDataValidation(sheet, dv); err != nil {
+				return err
+			}
+		}
+		if hint, ok := fieldHints[f.Key]; ok {
+			if err := fx.AddComment(sheet, excelize.Comment{Cell: start, Author: "求人票", Text: f.Label + ": " + hint}); err != nil {
+				return err
+			}
+		}
+	}
+
+	// 印刷範囲をテンプレートの読み取り範囲にする（sheetReadRange が使う）
+	lastCol, _ := excelize.ColumnNumberToName(gridCols)
+	if err := fx.SetDefinedName(&excelize.DefinedName{
+		Name:     "_xlnm.Print_Area",
+		RefersTo: fmt.Sprintf("'%s'!$A$1:$%s$%d", sheet, lastCol, gridRows),
+		Scope:    sheet,
+	}); err != nil {
+		return err
+	}
+	// A4縦・幅を1ページに収める
+	size, fitToWidth := 9, 1
+	if err := fx.SetPageLayout(sheet, &excelize.PageLayoutOptions{Size: &size, FitToWidth: &fitToWidth}); err != nil {
+		return err
+	}
+	// FitToWidth は「次のページ数に合わせて印刷」を選んだときだけ使われる
+	fitToPage := true
+	if err := fx.SetSheetProps(sheet, &excelize.SheetPropsOptions{FitToPage: &fitToPage}); err != nil {
+		return err
+	}
+	return fx.Write(w)
+}
+
+// 現行の項目マッピングから入力用のxlsxテンプレートをダウンロードフォルダに保存し、保存したパスを返す
+func (a *App) SaveBlankTemplate() (string, error) {
+	Dpath, err := GetDownloadsPath()
+	if err != nil {
+		return "", fmt.Errorf("ダウンロードパスの取得に失敗: %w", err)
+	}
+	path := uniquePath(filepath.Join(Dpath, blankTemplateName))
+	if err := writeFile(path, WriteBlankTemplate); err != nil {
+		return "", fmt.Errorf("テンプレートの出力に失敗: %w", err)
+	}
+	fmt.Printf("テンプレートを保存しました: %s\n", path)
+	return path, nil
+}
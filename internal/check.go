@@ -0,0 +1,301 @@
+package internal
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/xuri/excelize/v2"
+	"golang.org/x/image/font/sfnt"
+)
+
+// 事前チェックで見つかる問題の種類
+const (
+	issueOpen        = "ファイルを開けません"
+	issueRuleError   = "入力エラー（PDFを出力しません）"
+	issueRuleWarning = "入力の注意"
+	issueTooWide     = "枠の幅を超えるため出力されません"
+	issueOffPage     = "ページに収まらないため出力されません"
+	issueTooLong     = "2ページを超えるため出力されません"
+	issueClipped     = "セルに収まらないため一部が出力されません"
+	issueUnknownChar = "フォントにない文字があります"
+)
+
+const (
+	checkNoIssue      = "問題なし"    // 問題のないシートの行
+	maxUnknownChars   = 5         // 詳細に列挙するフォントにない文字の数
+	checkReportPrefix = "事前チェック_" // 保存するファイル名の先頭
+)
+
+// CheckIssue: 事前チェックで見つかった問題
+type CheckIssue struct {
+	File   string `json:"file"`
+	Sheet  string `json:"sheet"`
+	Field  string `json:"field"`  // 項目名
+	Cell   string `json:"cell"`   // セル（例: C9）
+	Kind   string `json:"kind"`   // 問題の種類
+	Detail string `json:"detail"` // 対象の文字列など
+}
+
+// CheckSheet: シートごとのチェック結果
+type CheckSheet struct {
+	File   string `json:"file"`
+	Sheet  string `json:"sheet"`
+	Pages  int    `json:"pages"`  // PDFのページ数
+	Issues int    `json:"issues"` // 問題の数
+}
+
+// CheckReport: 事前チェックの結果
+type CheckReport struct {
+	Sheets   []CheckSheet `json:"sheets"`
+	Issues   []CheckIssue `json:"issues"`
+	Warnings []string     `json:"warnings"` // 読み込み中の注意事項
+}
+
+// ファイルを変換せずに描画だけ行い、出力されない文字列や空の必須項目などを報告する。
+// opts のうち、読み込みの設定（パスワード・表示形式・シートの書式どおりの描画・変換するシート・差し込み・画像の配置先）と入力ルールを使う
+func (a *App) CheckXLSXs(files []FileData, opts ConvertOptions) (CheckReport, error) {
+	var report CheckReport
+
+	fontFile, fontPath, err := a.loadFont()
+	if err != nil {
+		return report, err
+	}
+	defer fontFile.Close()
+	defer os.Remove(fontPath)
+
+	ft, err := parseFont()
+	if err != nil {
+		return report, err
+	}
+
+	rules, err := compileRules(opts.Rules)
+	if err != nil {
+		return report, err
+	}
+
+	files, warnings, err := expandArchives(files)
+	report.Warnings = append(report.Warnings, warnings...)
+	if err != nil {
+		return report, err
+	}
+
+	for _, f := range files {
+		loaded, err := a.loadPostings(f, loadOptions{
+			passwords: append([]string{f.Password}, opts.Passwords...),
+			formats:   opts.Formats,
+			layout:    opts.SheetLayout,
+			sheets:    opts.Sheets,
+			mailMerge: opts.MailMerge,
+		})
+		if err != nil {
+			// 開けないファイルも問題として報告し、残りのファイルのチェックを続ける
+			report.Issues = append(report.Issues, CheckIssue{File: f.Name, Kind: issueOpen, Detail: err.Error()})
+			continue
+		}
+		for _, w := range loaded.warnings {
+			report.Warnings = append(report.Warnings, f.Name+" "+w)
+		}
+		for i, tableData := range loaded.postings {
+			pdf, layouts := buildPostingPDF(fontPath, loaded.sheet(i), opts.ImageSlot)
+			sheetLayout := i < len(loaded.layouts) && loaded.layouts[i] != nil
+			issues := checkPosting(tableData, layouts[0], sheetLayout, ft, rules)
+			for j := range issues {
+				issues[j].File, issues[j].Sheet = f.Name, loaded.sheets[i]
+			}
+			report.Issues = append(report.Issues, issues...)
+			report.Sheets = append(report.Sheets, CheckSheet{File: f.Name, Sheet: loaded.sheets[i], Pages: pdf.PageNo(), Issues: len(issues)})
+		}
+	}
+	return report, nil
+}
+
+// checkPosting は1シートの描画結果から問題を集める。
+// sheetLayout はシートの書式どおりに描画した場合で、表の列・行がそのままシート上のセル位置になる
+func checkPosting(tableData [][]string, layout postingLayout, sheetLayout bool, ft *sfnt.Font, rules []fieldRule) []CheckIssue {
+	var issues []CheckIssue
+	for _, v := range checkRules(tableData, rules) {
+		kind := issueRuleWarning
+		if v.severity == severityError {
+			kind = issueRuleError
+		}
+		issues = append(issues, CheckIssue{Field: v.field.Label, Cell: cellName(v.field.Ref), Kind: kind, Detail: v.message})
+	}
+
+	// 表ごとの出力できなかったセル（最後の表は付録。シートの書式どおりの場合はページごとの表）
+	sections := append([]Section{idSection}, postingSections...)
+	for ti, t := range layout.tables {
+		for _, problem := range t.problems {
+			ref := appendixRef
+			switch {
+			case sheetLayout:
+				ref = at(problem.row_i, problem.col_i)
+			case ti < len(sections):
+				ref = sectionCellRef(sections[ti], problem.col_i, problem.row_i)
+			}
+			issues = append(issues, CheckIssue{Field: refLabel(ref), Cell: cellName(ref), Kind: problem.kind, Detail: problem.text})
+		}
+	}
+
+	var buf sfnt.Buffer
+	for _, f := range postingFields {
+		if chars := unknownChars(ft, &buf, f.Ref.Value(tableData)); chars != "" {
+			issues = append(issues, CheckIssue{Field: f.Label, Cell: cellName(f.Ref), Kind: issueUnknownChar, Detail: chars})
+		}
+	}
+	return issues
+}
+
+// sectionCellRef は表の列・行から始まるセルの、シート上の位置を返す
+func sectionCellRef(s Section, col, row int) CellRef {
+	for _, c := range s.cells {
+		if c.col_i == col && c.row_i == row {
+			return c.src
+		}
+	}
+	return at(-1, -1)
+}
+
+// refLabel はセルの項目名を返す（項目でないセルはラベル・タイトルとする）
+func refLabel(ref CellRef) string {
+	if f, ok := fieldByRef[ref]; ok {
+		return f.Label
+	}
+	return "ラベル"
+}
+
+// cellName はセル位置を「C9」のような名前にする（範囲外は空文字）
+func cellName(ref CellRef) string {
+	name, err := excelize.CoordinatesToCellName(ref.Col+1, ref.Row+1)
+	if err != nil {
+		return ""
+	}
+	return name
+}
+
+// unknownChars はフォントにない文字を重複なく返す（maxUnknownChars 文字まで）
+func unknownChars(ft *sfnt.Font, buf *sfnt.Buffer, text string) string {
+	seen := map[rune]bool{}
+	var chars []string
+	count := 0
+	for _, r := range text {
+		if unicode.IsControl(r) || seen[r] {
+			continue
+		}
+		seen[r] = true
+		if i, err := ft.GlyphIndex(buf, r); err == nil && i != 0 {
+			continue
+		}
+		count++
+		if len(chars) < maxUnknownChars {
+			chars = append(chars, fmt.Sprintf("「%c」(U+%04X)", r, r))
+		}
+	}
+	if count > len(chars) {
+		chars = append(chars, fmt.Sprintf("ほか%d文字", count-len(chars)))
+	}
+	return strings.Join(chars, " ")
+}
+
+// checkReportRows は事前チェックの結果を表にする（1行目は見出し）。
+// シートの順に問題ごとに1行とし、問題のないシートは「問題なし」の1行にする
+func checkReportRows(report CheckReport) [][]string {
+	rows := [][]string{{"ファイル", "シート", "ページ数", "項目", "セル", "問題", "詳細"}}
+	bySheet := map[[2]string][]CheckIssue{}
+	for _, issue := range report.Issues {
+		key := [2]string{issue.File, issue.Sheet}
+		bySheet[key] = append(bySheet[key], issue)
+	}
+	for _, s := range report.Sheets {
+		key := [2]string{s.File, s.Sheet}
+		pages := strconv.Itoa(s.Pages)
+		if len(bySheet[key]) == 0 {
+			rows = append(rows, []string{s.File, s.Sheet, pages, "", "", checkNoIssue, ""})
+		}
+		for _, issue := range bySheet[key] {
+			rows = append(rows, []string{issue.File, issue.Sheet, pages, issue.Field, issue.Cell, issue.Kind, issue.Detail})
+		}
+		delete(bySheet, key)
+	}
+	// 開けなかったファイルなど、シートのチェック結果がない問題
+	for _, issue := range report.Issues {
+		if _, ok := bySheet[[2]string{issue.File, issue.Sheet}]; ok {
+			rows = append(rows, []string{issue.File, issue.Sheet, "", issue.Field, issue.Cell, issue.Kind, issue.Detail})
+		}
+	}
+	return rows
+}
+
+// WriteCheckReportCSV は事前チェックの結果をCSVで書き出す（Excelで開けるようにBOM付きUTF-8にする）
+func WriteCheckReportCSV(w io.Writer, report CheckReport) error {
+	if _, err := w.Write(utf8BOM); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.WriteAll(checkReportRows(report)); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// WriteCheckReportXLSX は事前チェックの結果をxlsxで書き出す
+func WriteCheckReportXLSX(w io.Writer, report CheckReport) error {
+	fx := excelize.NewFile()
+	defer fx.Close()
+	sheet := "事前チェック"
+	if err := fx.SetSheetName("Sheet1", sheet); err != nil {
+		return err
+	}
+	rows := checkReportRows(report)
+	for r, row := range rows {
+		cell, _ := excelize.CoordinatesToCellName(1, r+1)
+		values := make([]any, len(row))
+		for i, v := range row {
+			values[i] = v
+		}
+		if err := fx.SetSheetRow(sheet, cell, &values); err != nil {
+			return err
+		}
+	}
+	if err := fx.SetColWidth(sheet, "A", "B", 24); err != nil {
+		return err
+	}
+	if err := fx.SetColWidth(sheet, "F", "G", 40); err != nil {
+		return err
+	}
+	// 見出しを固定し、絞り込めるようにする
+	if err := fx.SetPanes(sheet, &excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+		return err
+	}
+	last, _ := excelize.CoordinatesToCellName(len(rows[0]), len(rows))
+	if err := fx.AutoFilter(sheet, "A1:"+last, nil); err != nil {
+		return err
+	}
+	return fx.Write(w)
+}
+
+// 事前チェックの結果を format（"xlsx" または "csv"）でダウンロードフォルダに保存し、保存したパスを返す
+func (a *App) SaveCheckReport(report CheckReport, format string) (string, error) {
+	Dpath, err := GetDownloadsPath()
+	if err != nil {
+		return "", fmt.Errorf("ダウンロードパスの取得に失敗: %w", err)
+	}
+	write := func(w io.Writer) error { return WriteCheckReportXLSX(w, report) }
+	if format == "csv" {
+		write = func(w io.Writer) error { return WriteCheckReportCSV(w, report) }
+	} else {
+		format = "xlsx"
+	}
+	path := uniquePath(filepath.Join(Dpath, checkReportPrefix+time.Now().Format("20060102")+"."+format))
+	if err := writeFile(path, write); err != nil {
+		return "", fmt.Errorf("事前チェックの結果の出力に失敗: %w", err)
+	}
+	fmt.Printf("事前チェックの結果を保存しました: %s\n", path)
+	return path, nil
+}
@@ -0,0 +1,270 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/image/font/opentype"
+)
+
+// ConvertOptions: 1回の変換で出力する形式
+type ConvertOptions struct {
+	PDF          bool              `json:"pdf"`
+	HTML         bool              `json:"html"`
+	DOCX         bool              `json:"docx"`
+	JSON         bool              `json:"json"`
+	JSONLD       bool              `json:"jsonld"` // Google for Jobs 用の構造化データ
+	Feed         bool              `json:"feed"`   // バッチ全体で1つの求人フィード（Indeed形式のXML）
+	Text         bool              `json:"text"`   // 求人サイトへの貼り付け用のテキスト
+	Markdown     bool              `json:"markdown"`
+	FeedURL      string            `json:"feedUrl"`      // 求人フィードの url にする求人ページのURL（「{id}」は求人番号に置き換える）
+	CharLimits   map[string]int    `json:"charLimits"`   // テキスト・Markdownの項目ごとの文字数制限（キーは項目のJSONキーまたは項目名）
+	Passwords    []string          `json:"passwords"`    // パスワード付きのファイルに試す共通のパスワード
+	Formats      map[string]string `json:"formats"`      // 項目ごとの表示形式（キーは項目のJSONキーまたは項目名）
+	SheetLayout  bool              `json:"sheetLayout"`  // PDF・サムネイルをテンプレートのレイアウトではなくシートの書式どおりに描画する
+	ImageSlot    string            `json:"imageSlot"`    // 元の位置に置けない画像の配置先（"footer"・"header"・"none"。空の場合は "footer"）
+	Sheets       SheetSelection    `json:"sheets"`       // 変換するシート（空の場合はすべてのシート）
+	PDFPerSheet  bool              `json:"pdfPerSheet"`  // PDFをシートごとに分け、各シートの職種・勤務地から名前を付ける（差し込みの場合は1行ごと）
+	MailMerge    bool              `json:"mailMerge"`    // シートを求人の一覧（1行目が見出し）として読み、1行を1件の求人票にする
+	Rules        []FieldRule       `json:"rules"`        // 入力ルール（nil の場合は既定のルール。重大度 error の違反があるシートはどの形式でも出力しない）
+	Thumbnails   bool              `json:"thumbnails"`   // ページごとのPNGサムネイル
+	ThumbnailDPI float64           `json:"thumbnailDpi"` // サムネイルの解像度（0の場合は thumbnailDPI）
+}
+
+// ConvertResult: 変換結果（保存したファイルと注意事項）
+type ConvertResult struct {
+	Files    []string `json:"files"`
+	Warnings []string `json:"warnings"`
+	Blocked  []string `json:"blocked"` // 入力エラーのため出力しなかったシート（「ファイル名 シート名」）
+}
+
+// xlsxファイルを読み込み、選択された形式の求人票をダウンロードフォルダに保存（ZIPファイルは展開して変換）
+func (a *App) ConvertXLSXs(files []FileData, opts ConvertOptions) (result ConvertResult, err error) {
+	// 変換中のパニックは、変換に成功したように見えないようエラーにする
+	defer func() {
+		if r := recover(); r != nil {
+			log.Println("Recovered from panic:", r)
+			err = fmt.Errorf("変換中にエラーが発生しました: %v", r)
+		}
+	}()
+
+	// フォントファイルの読み込み（PDFとサムネイルで使用）
+	var fontPath string
+	if opts.PDF || opts.Thumbnails {
+		fontFile, path, err := a.loadFont()
+		if err != nil {
+			return result, err
+		}
+		defer fontFile.Close()
+		defer os.Remove(path)
+		fontPath = path
+	}
+
+	var thumbFont *opentype.Font
+	if opts.Thumbnails {
+		f, err := parseFont()
+		if err != nil {
+			return result, err
+		}
+		thumbFont = f
+	}
+	dpi := opts.ThumbnailDPI
+	if dpi <= 0 {
+		dpi = thumbnailDPI
+	}
+
+	Dpath, err := GetDownloadsPath()
+	if err != nil {
+		return result, fmt.Errorf("ダウンロードパスの取得に失敗: %w", err)
+	}
+
+	rules, err := compileRules(opts.Rules)
+	if err != nil {
+		return result, err
+	}
+
+	// ZIPファイルは中の表計算ファイルに展開する
+	files, warnings, err := expandArchives(files)
+	result.Warnings = append(result.Warnings, warnings...)
+	if err != nil {
+		return result, err
+	}
+
+	var feedSources []FeedSource
+	for _, f := range files {
+		loaded, err := a.loadPostings(f, loadOptions{
+			passwords: append([]string{f.Password}, opts.Passwords...),
+			formats:   opts.Formats,
+			layout:    opts.SheetLayout,
+			sheets:    opts.Sheets,
+			mailMerge: opts.MailMerge,
+		})
+		if err != nil {
+			return result, err
+		}
+		for _, w := range loaded.warnings {
+			result.Warnings = append(result.Warnings, f.Name+" "+w)
+		}
+		// 入力ルールの違反は注意事項にし、重大度 error の違反があるシートはどの形式でも出力しない
+		ruleWarnings, blocked := loaded.checkRules(rules)
+		for _, w := range ruleWarnings {
+			result.Warnings = append(result.Warnings, f.Name+" "+w)
+		}
+		if len(blocked) > 0 {
+			for i, sheet := range loaded.sheets {
+				if blocked[i] {
+					result.Blocked = append(result.Blocked, f.Name+" "+sheet)
+				}
+			}
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: 入力エラーがあるシート（%s）は出力しませんでした", f.Name, loaded.blockedSheets(blocked)))
+			loaded = loaded.without(blocked)
+			if len(loaded.postings) == 0 {
+				continue
+			}
+		}
+		postings := loaded.postings
+		// ZIP内のファイルはフォルダ構成をそのまま出力先にする
+		outDir, err := outputDir(Dpath, f.Name)
+		if err != nil {
+			return result, err
+		}
+		if opts.Feed {
+			feedSources = append(feedSources, FeedSource{Name: f.Name, Postings: postings})
+		}
+
+		if opts.PDF || opts.Thumbnails {
+			pdf, layouts := buildPostingPDF(fontPath, loaded, opts.ImageSlot)
+			pageNum := pdf.PageNo()
+			if opts.PDF && opts.PDFPerSheet {
+				paths, err := saveSheetPDFs(fontPath, f.Name, loaded, opts.ImageSlot, outDir)
+				for _, pdfPath := range paths {
+					fmt.Printf("PDFファイルを保存しました: %s\n", pdfPath)
+				}
+				result.Files = append(result.Files, paths...)
+				if err != nil {
+					return result, fmt.Errorf("%s のPDF出力に失敗: %w", f.Name, err)
+				}
+			} else if opts.PDF {
+				pdfPath := uniquePath(filepath.Join(outDir, postingFileName(f.Name, postings, ".pdf")))
+				if err := pdf.OutputFileAndClose(pdfPath); err != nil {
+					return result, fmt.Errorf("%s のPDF出力に失敗: %w", f.Name, err)
+				}
+				fmt.Printf("PDFファイルを保存しました: %s\n", pdfPath)
+				result.Files = append(result.Files, pdfPath)
+			}
+			if opts.Thumbnails {
+				for i, img := range rasterizePages(thumbFont, layouts, pageNum, dpi) {
+					pngPath := uniquePath(filepath.Join(outDir, postingFileName(f.Name, postings, fmt.Sprintf("_%d.png", i+1))))
+					if err := writeFile(pngPath, func(w io.Writer) error { return WritePNG(w, img) }); err != nil {
+						return result, fmt.Errorf("%s のサムネイル出力に失敗: %w", f.Name, err)
+					}
+					fmt.Printf("サムネイルを保存しました: %s\n", pngPath)
+					result.Files = append(result.Files, pngPath)
+				}
+			}
+		}
+		if opts.HTML {
+			htmlPath := uniquePath(filepath.Join(outDir, postingFileName(f.Name, postings, ".html")))
+			if err := writeFile(htmlPath, func(w io.Writer) error { return WritePostingHTML(w, postings) }); err != nil {
+				return result, fmt.Errorf("%s のHTML出力に失敗: %w", f.Name, err)
+			}
+			fmt.Printf("HTMLファイルを保存しました: %s\n", htmlPath)
+			result.Files = append(result.Files, htmlPath)
+		}
+		if opts.DOCX {
+			docxPath := uniquePath(filepath.Join(outDir, postingFileName(f.Name, postings, ".docx")))
+			if err := writeFile(docxPath, func(w io.Writer) error { return WritePostingDOCX(w, postings) }); err != nil {
+				return result, fmt.Errorf("%s のDOCX出力に失敗: %w", f.Name, err)
+			}
+			fmt.Printf("DOCXファイルを保存しました: %s\n", docxPath)
+			result.Files = append(result.Files, docxPath)
+		}
+		if opts.JSONLD {
+			ldPath := uniquePath(filepath.Join(outDir, postingFileName(f.Name, postings, ".jsonld")))
+			var warnings []string
+			err := writeFile(ldPath, func(w io.Writer) error {
+				ws, err := WritePostingJSONLD(w, postings, time.Now())
+				warnings = ws
+				return err
+			})
+			if err != nil {
+				return result, fmt.Errorf("%s のJSON-LD出力に失敗: %w", f.Name, err)
+			}
+			for _, w := range warnings {
+				result.Warnings = append(result.Warnings, f.Name+" "+w)
+			}
+			fmt.Printf("JSON-LDファイルを保存しました: %s\n", ldPath)
+			result.Files = append(result.Files, ldPath)
+		}
+		for _, t := range []struct {
+			enabled bool
+			ext     string
+			name    string
+			write   func(io.Writer, [][][]string, map[string]int) ([]string, error)
+		}{
+			{opts.Text, ".txt", "テキスト", WritePostingText},
+			{opts.Markdown, ".md", "Markdown", WritePostingMarkdown},
+		} {
+			if !t.enabled {
+				continue
+			}
+			textPath := uniquePath(filepath.Join(outDir, postingFileName(f.Name, postings, t.ext)))
+			var warnings []string
+			err := writeFile(textPath, func(w io.Writer) error {
+				ws, err := t.write(w, postings, opts.CharLimits)
+				warnings = ws
+				return err
+			})
+			if err != nil {
+				return result, fmt.Errorf("%s の%s出力に失敗: %w", f.Name, t.name, err)
+			}
+			for _, w := range warnings {
+				result.Warnings = append(result.Warnings, f.Name+" "+w)
+			}
+			fmt.Printf("%sファイルを保存しました: %s\n", t.name, textPath)
+			result.Files = append(result.Files, textPath)
+		}
+		if opts.JSON {
+			jsonPath := uniquePath(filepath.Join(outDir, postingFileName(f.Name, postings, ".json")))
+			if err := writeFile(jsonPath, func(w io.Writer) error { return WritePostingJSON(w, postings) }); err != nil {
+				return result, fmt.Errorf("%s のJSON出力に失敗: %w", f.Name, err)
+			}
+			fmt.Printf("JSONファイルを保存しました: %s\n", jsonPath)
+			result.Files = append(result.Files, jsonPath)
+		}
+	}
+
+	if opts.Feed && len(feedSources) > 0 {
+		feedPath := uniquePath(filepath.Join(Dpath, "求人フィード_"+time.Now().Format("20060102")+".xml"))
+		var warnings []string
+		err := writeFile(feedPath, func(w io.Writer) error {
+			ws, err := WriteJobFeed(w, feedSources, opts.FeedURL, time.Now())
+			warnings = ws
+			return err
+		})
+		result.Warnings = append(result.Warnings, warnings...)
+		if err != nil {
+			return result, fmt.Errorf("求人フィードの出力に失敗: %w", err)
+		}
+		fmt.Printf("求人フィードを保存しました: %s\n", feedPath)
+		result.Files = append(result.Files, feedPath)
+	}
+	return result, nil
+}
+
+// writeFile はファイルを作成してwriteで書き込む
+func writeFile(path string, write func(w io.Writer) error) error {
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(out); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
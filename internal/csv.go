@@ -1,18 +1,18 @@
 package internal
 
 import (
-	"encoding/base64"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/xuri/excelize/v2"
 )
 
-func (a *App) loadCSV(f FileData) (*excelize.File, string, error) {
-	data, err := base64.StdEncoding.DecodeString(f.Data)
+func (a *App) loadCSV(f FileData, opts ...excelize.Options) (*excelize.File, string, error) {
+	data, err := decodeFileData(f)
 	if err != nil {
-		return nil, "", fmt.Errorf("%s のデコードに失敗: %w", f.Name, err)
+		return nil, "", err
 	}
 	// xlsxファイルは保存せず、メモリ上で処理
 	tmpFile, err := os.CreateTemp("", "tmpxlsx-*.xlsx")
@@ -29,7 +29,7 @@ func (a *App) loadCSV(f FileData) (*excelize.File, string, error) {
 	tmpFile.Close()
 
 	// Excelファイルを開く
-	fx, err := excelize.OpenFile(tmpFile.Name())
+	fx, err := excelize.OpenFile(tmpFile.Name(), opts...)
 	name := filepath.Base(f.Name)
 	if err != nil {
 		return nil, "", fmt.Errorf("%s のExcel読込に失敗: %w", f.Name, err)
@@ -37,36 +37,172 @@ func (a *App) loadCSV(f FileData) (*excelize.File, string, error) {
 	return fx, name, nil
 }
 
+// loadOptions: 入力ファイルの読み込み設定
+type loadOptions struct {
+	passwords []string          // パスワード付きのファイルに順に試すパスワード
+	formats   map[string]string // 項目ごとの表示形式（キーは項目のJSONキーまたは項目名）
+	layout    bool              // シートの書式（結合セル・列幅・罫線など）も読み込むか
+	sheets    SheetSelection    // 読み込むシート
+	mailMerge bool              // シートを求人の一覧として読み、1行を1件の求人票にする
+}
+
+// loadedPostings: 読み込んだ全シートのデータ
+type loadedPostings struct {
+	postings [][][]string
+	sheets   []string                // シート名（差し込みの場合は「シート名 N行目」）
+	layouts  []*sheetLayout          // シートごとの書式（loadOptions.layout の場合。xlsx以外のシートは nil）
+	richText []map[CellRef][]TextRun // シートごとの書式付きの文字列（xlsx以外のシートは nil）
+	pictures [][]sheetPicture        // シートごとの画像（xlsx以外のシートは nil）
+	warnings []string                // 読み込み中の注意事項（計算できなかった数式など）
+}
+
+// loadPostings はファイルを開き、opts.sheets で選んだシートのデータを読み込む
+func (a *App) loadPostings(f FileData, opts loadOptions) (loadedPostings, error) {
+	var loaded loadedPostings
+	if err := opts.sheets.validate(); err != nil {
+		return loaded, err
+	}
+	wb, err := a.openWorkbook(f, opts.passwords)
+	if err != nil {
+		return loaded, err
+	}
+	defer wb.Close()
+
+	sheets := wb.SheetList()
+	if len(sheets) == 0 {
+		return loaded, fmt.Errorf("%s: シートがありません", f.Name)
+	}
+	// CSV/TSVは見出しがあれば読み込み時に1行1件にしているため、差し込みはExcel・ODSのシートだけに行う
+	ext := strings.ToLower(filepath.Ext(f.Name))
+	mailMerge := opts.mailMerge && ext != ".csv" && ext != ".tsv"
+	if xw, ok := wb.(*xlsxWorkbook); ok {
+		xw.list = mailMerge
+	}
+
+	for _, sheet := range sheets {
+		if !opts.sheets.selects(wb, sheet) {
+			continue
+		}
+		tableData, err := wb.Grid(sheet)
+		if err != nil {
+			return loaded, err
+		}
+		if mailMerge {
+			merged, err := mergeSheet(sheet, tableData)
+			if err != nil {
+				return loaded, fmt.Errorf("%s の差し込みに失敗: %w", f.Name, err)
+			}
+			for _, m := range merged {
+				applyFieldFormats(m.grid, opts.formats)
+				loaded.postings = append(loaded.postings, m.grid)
+				loaded.sheets = append(loaded.sheets, fmt.Sprintf("%s %d行目", sheet, m.row))
+				loaded.layouts = append(loaded.layouts, nil)
+				loaded.richText = append(loaded.richText, nil)
+				loaded.pictures = append(loaded.pictures, nil)
+			}
+			continue
+		}
+		// 古い版のテンプレートは現行のセル位置に移し替える。シートの書式などは移し替える前の表（sheetData）で読む
+		sheetData := tableData
+		tableData, refs, warnings := migrateTemplate(sheet, sheetData)
+		loaded.warnings = append(loaded.warnings, warnings...)
+		applyFieldFormats(tableData, opts.formats)
+		var layout *sheetLayout
+		var rich map[CellRef][]TextRun
+		var pictures []sheetPicture
+		if xw, ok := wb.(*xlsxWorkbook); ok {
+			rich = remapRichText(readRichText(xw.fx, sheet, sheetData), refs)
+			cols := 0
+			if len(sheetData) > 0 {
+				cols = len(sheetData[0])
+			}
+			if pictures, warnings, err = readPictures(xw.fx, sheet, len(sheetData), cols); err != nil {
+				return loaded, fmt.Errorf("シート %s の画像の読み込みに失敗: %w", sheet, err)
+			}
+			loaded.warnings = append(loaded.warnings, warnings...)
+			if opts.layout {
+				if layout, err = readSheetLayout(xw.fx, sheet, sheetData, pictures); err != nil {
+					return loaded, fmt.Errorf("シート %s の書式の読み込みに失敗: %w", sheet, err)
+				}
+			} else {
+				pictures = remapPictures(pictures, refs)
+			}
+		}
+		loaded.postings = append(loaded.postings, tableData)
+		loaded.sheets = append(loaded.sheets, sheet)
+		loaded.layouts = append(loaded.layouts, layout)
+		loaded.richText = append(loaded.richText, rich)
+		loaded.pictures = append(loaded.pictures, pictures)
+	}
+	if len(loaded.postings) == 0 {
+		return loaded, fmt.Errorf("%s: シートの選択条件に合うシートがありません", f.Name)
+	}
+	loaded.warnings = append(wb.Warnings(), loaded.warnings...)
+	return loaded, nil
+}
+
 // シート名取得（最初のシート）
 
-func (a *App) loadData(sheet string, fx *excelize.File) ([][]string, error) {
+// list の場合はシートを求人の一覧として読むため、テンプレートの範囲外の入力を警告しない
+func (a *App) loadData(sheet string, fx *excelize.File, list bool) ([][]string, []string, error) {
 
-	// A1:AD48のデータ取得
+	// 印刷範囲・名前付き範囲・使用範囲のデータ取得（A1:AD48より狭くはしない）
+	readRows, readCols := sheetReadRange(fx, sheet)
+	if list {
+		// 一覧はテンプレートの範囲と関係なく、上限まで読む
+		readRows, readCols = maxReadRows, maxReadCols
+	}
 	rows, err := fx.Rows(sheet)
 	if err != nil {
-		return nil, fmt.Errorf("範囲取得失敗: %w", err)
+		return nil, nil, fmt.Errorf("範囲取得失敗: %w", err)
 	}
 	var tableData [][]string
+	var warnings []string
+	var sheetRows [][]string // 範囲外の入力を調べるための読み取り範囲外も含む行
+	truncated := false       // 上限の行数・列数を超えて読み込まなかった値があるか
+	formatter := newCellFormatter(fx, sheet)
 	rowIdx := 0
 	for rows.Next() {
 		row, err := rows.Columns()
 		if err != nil {
-			return nil, fmt.Errorf("行取得失敗: %w", err)
+			return nil, nil, fmt.Errorf("行取得失敗: %w", err)
 		}
-		if rowIdx >= 48 {
-			break
+		if rowIdx >= maxReadRows {
+			if strings.Join(row, "") != "" {
+				truncated = true
+				break
+			}
+			rowIdx++
+			continue
 		}
-		// 30列分だけ取得
-		rowData := make([]string, 30)
-		for i := 0; i < 30; i++ {
-			if i < len(row) {
-				rowData[i] = row[i]
-			} else {
-				rowData[i] = ""
+		sheetRows = append(sheetRows, row)
+		if readCols == maxReadCols && len(row) > readCols && strings.Join(row[readCols:], "") != "" {
+			truncated = true
+		}
+		if rowIdx >= readRows {
+			rowIdx++
+			continue
+		}
+		rowData := make([]string, readCols)
+		for i := 0; i < readCols && i < len(row); i++ {
+			rowData[i] = formatter.value(rowIdx, i, row[i])
+			// 他のツールで作成したファイルは数式の計算結果が保存されていないことがある
+			if rowData[i] == "" {
+				value, warning := formatter.formula(rowIdx, i)
+				rowData[i] = value
+				if warning != "" {
+					warnings = append(warnings, warning)
+				}
 			}
 		}
 		tableData = append(tableData, rowData)
 		rowIdx++
 	}
-	return tableData, nil
+	if w := outsideLayoutWarning(sheet, sheetRows); w != "" && !list {
+		warnings = append(warnings, w)
+	}
+	if truncated {
+		warnings = append(warnings, truncatedWarning(sheet))
+	}
+	return tableData, warnings, nil
 }
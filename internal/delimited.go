@@ -0,0 +1,115 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// 1行目の見出しのうち、項目マッピングと一致するものがこの数以上あれば1行1求人のCSVとして読む
+const minHeaderMatches = 2
+
+var (
+	utf8BOM    = []byte{0xEF, 0xBB, 0xBF}
+	utf16LEBOM = []byte{0xFF, 0xFE}
+	utf16BEBOM = []byte{0xFE, 0xFF}
+)
+
+// decodeText は文字コードを判定してUTF-8に変換する（UTF-8・BOM付きUTF-8・UTF-16・Shift_JIS/CP932）
+func decodeText(data []byte) (string, string, error) {
+	switch {
+	case bytes.HasPrefix(data, utf8BOM):
+		return string(data[len(utf8BOM):]), "UTF-8 (BOM)", nil
+	case bytes.HasPrefix(data, utf16LEBOM), bytes.HasPrefix(data, utf16BEBOM):
+		out, _, err := transform.Bytes(unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM).NewDecoder(), data)
+		if err != nil {
+			return "", "", fmt.Errorf("UTF-16の変換に失敗: %w", err)
+		}
+		return string(out), "UTF-16", nil
+	case utf8.Valid(data):
+		return string(data), "UTF-8", nil
+	}
+	// japanese.ShiftJIS はCP932（Windows-31J）の拡張文字も変換できる
+	out, _, err := transform.Bytes(japanese.ShiftJIS.NewDecoder(), data)
+	if err != nil {
+		return "", "", fmt.Errorf("Shift_JISの変換に失敗: %w", err)
+	}
+	return string(out), "Shift_JIS", nil
+}
+
+// detectDelimiter は拡張子と1行目から区切り文字を決める
+func detectDelimiter(name, text string) rune {
+	if strings.ToLower(filepath.Ext(name)) == ".tsv" {
+		return '\t'
+	}
+	first := strings.SplitN(text, "\n", 2)[0]
+	if strings.Count(first, "\t") > strings.Count(first, ",") {
+		return '\t'
+	}
+	return ','
+}
+
+// fieldByHeader は見出し（JSONキーまたは項目名）から項目を探す
+func fieldByHeader(header string) (FieldDef, bool) {
+	header = strings.TrimSpace(header)
+	for _, f := range postingFields {
+		if header == f.Key || header == f.Label {
+			return f, true
+		}
+	}
+	return FieldDef{}, false
+}
+
+// readDelimited はCSV/TSVを読み込む。
+// 1行目が項目名の見出しなら1行を1件の求人票としてテンプレートの表に割り当て、
+// そうでなければテンプレートのシートをCSVに書き出したものとしてそのまま表にする
+func readDelimited(name string, data []byte) (*gridWorkbook, error) {
+	text, encoding, err := decodeText(data)
+	if err != nil {
+		return nil, err
+	}
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	r := csv.NewReader(strings.NewReader(text))
+	r.Comma = detectDelimiter(name, text)
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	var rows [][]string
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s として読み込めません: %w", encoding, err)
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("データがありません")
+	}
+
+	w := newGridWorkbook()
+	fields := headerFields(rows[0])
+	if len(fields) < minHeaderMatches {
+		sheet := strings.TrimSuffix(filepath.Base(name), filepath.Ext(name))
+		grid, truncated := toGrid(rows)
+		w.add(sheet, grid)
+		if truncated {
+			w.addTruncated(sheet)
+		}
+		return w, nil
+	}
+	for _, m := range mergeRows(rows, fields) {
+		w.add(fmt.Sprintf("%d行目", m.row), m.grid)
+	}
+	return w, nil
+}
@@ -0,0 +1,319 @@
+package internal
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// DOCXのページ設定（PDFの renderPosting と同じ寸法、単位はmm）
+const (
+	docxPageW      = 210.0
+	docxPageH      = 297.0
+	docxMarginSide = 30.0
+	docxMarginTop  = 13.0
+	docxTitleW     = 5.0 // 縦書きタイトル列の幅
+	docxColNum     = 9   // 表の列数
+	docxFontSize   = 6.0 // 表のデフォルトフォントサイズ（pt）
+	docxRowH       = 4.5 // デフォルトの行の高さ
+	docxFillColor  = "99CCFF"
+)
+
+const docxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>
+<Override PartName="/word/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"/>
+</Types>`
+
+const docxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>
+</Relationships>`
+
+const docxDocumentRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>
+</Relationships>`
+
+const docxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+<w:docDefaults>
+<w:rPrDefault><w:rPr><w:rFonts w:ascii="IPAexGothic" w:hAnsi="IPAexGothic" w:eastAsia="IPAexGothic" w:cs="IPAexGothic"/><w:sz w:val="12"/><w:szCs w:val="12"/><w:lang w:val="en-US" w:eastAsia="ja-JP"/></w:rPr></w:rPrDefault>
+<w:pPrDefault><w:pPr><w:spacing w:after="0" w:line="240" w:lineRule="auto"/></w:pPr></w:pPrDefault>
+</w:docDefaults>
+<w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/></w:style>
+</w:styles>`
+
+// mmをtwip（1/1440インチ）に変換する
+func mmToTwip(mm float64) int {
+	return int(math.Round(mm * 1440 / 25.4))
+}
+
+// ptをハーフポイントに変換する
+func ptToHalfPt(pt float64) int {
+	return int(math.Round(pt * 2))
+}
+
+// docxCell: Wordの表に置くセル。列は0がタイトル列、1〜9が表の列
+type docxCell struct {
+	text     string
+	gc_i     int // 開始グリッド列
+	gc_f     int // 終了グリッド列
+	row_i    int // 開始行
+	row_f    int // 終了行
+	align    string
+	fill     bool
+	fontSize float64
+	vertical bool // 縦書き
+}
+
+// WritePostingDOCX はシートごとの求人票を1つのWord文書（DOCX）として書き出す
+func WritePostingDOCX(w io.Writer, postings [][][]string) error {
+	var body strings.Builder
+	for index, tableData := range postings {
+		if index != 0 {
+			body.WriteString(`<w:p><w:r><w:br w:type="page"/></w:r></w:p>`)
+		}
+		writeDOCXPosting(&body, tableData)
+	}
+
+	zw := zip.NewWriter(w)
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", docxContentTypes},
+		{"_rels/.rels", docxRels},
+		{"word/_rels/document.xml.rels", docxDocumentRels},
+		{"word/styles.xml", docxStyles},
+		{"word/document.xml", docxDocument(body.String())},
+	}
+	for _, p := range parts {
+		fw, err := zw.Create(p.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, p.content); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func docxDocument(body string) string {
+	return `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>` + body +
+		fmt.Sprintf(`<w:sectPr><w:pgSz w:w="%d" w:h="%d"/><w:pgMar w:top="%d" w:right="%d" w:bottom="%d" w:left="%d" w:header="0" w:footer="0" w:gutter="0"/></w:sectPr>`,
+			mmToTwip(docxPageW), mmToTwip(docxPageH), mmToTwip(docxMarginTop), mmToTwip(docxMarginSide), mmToTwip(20), mmToTwip(docxMarginSide)) +
+		`</w:body></w:document>`
+}
+
+// writeDOCXPosting は1シート分の求人票を書き出す
+func writeDOCXPosting(b *strings.Builder, tableData [][]string) {
+	// TITLE
+	b.WriteString(`<w:p><w:pPr><w:jc w:val="center"/></w:pPr>`)
+	writeDOCXRuns(b, "求人票", 20)
+	b.WriteString(`</w:p>`)
+
+	// COMPANY NAME
+	b.WriteString(`<w:p><w:pPr><w:jc w:val="right"/></w:pPr>`)
+	writeDOCXRuns(b, agencyName, 7)
+	b.WriteString(`</w:p>`)
+
+	// TABLE ID（右寄せの2セル）
+	colW := (docxPageW - 2*docxMarginSide - docxTitleW) / docxColNum
+	var idCells []docxCell
+	for i, c := range idSection.cells {
+		idCells = append(idCells, docxCell{text: c.src.Value(tableData), gc_i: i, gc_f: i + 1, row_f: 1, align: c.align, fill: c.fill, fontSize: c.fontSize})
+	}
+	writeDOCXTable(b, []float64{colW, colW}, []float64{idSection.rowH}, idCells, 0.3, "right")
+	writeDOCXSpacer(b)
+
+	// TABLE A〜F
+	for _, s := range postingSections {
+		widths := []float64{docxTitleW}
+		for i := 0; i < docxColNum; i++ {
+			widths = append(widths, colW)
+		}
+		outer := 0.1
+		if s.outline {
+			outer = 0.3
+		}
+		writeDOCXTable(b, widths, s.docxRowHeights(), s.docxCells(tableData), outer, "left")
+		writeDOCXSpacer(b)
+	}
+
+	// Appendix
+	b.WriteString(`<w:p>`)
+	writeDOCXRuns(b, appendixRef.Value(tableData), docxFontSize)
+	b.WriteString(`</w:p>`)
+}
+
+// docxCells はセクションのセルをWordの表のセルに変換する
+func (s Section) docxCells(tableData [][]string) []docxCell {
+	var cells []docxCell
+	if s.title != nil {
+		cells = append(cells, docxCell{text: s.title.Value(tableData), gc_i: 0, gc_f: 1, row_i: 0, row_f: s.rowNum, align: "C", fill: true, fontSize: docxFontSize, vertical: true})
+	}
+	for _, c := range s.cells {
+		gc_i := c.col_i + 1
+		if c.kind == titledCell { // タイトル付きセルはタイトル列まで広げる
+			gc_i = c.col_i
+		}
+		fontSize := c.fontSize
+		if fontSize < 0 {
+			fontSize = docxFontSize
+		}
+		cells = append(cells, docxCell{text: c.src.Value(tableData), gc_i: gc_i, gc_f: c.col_f + 1, row_i: c.row_i, row_f: min(c.row_f, s.rowNum), align: c.align, fill: c.fill, fontSize: fontSize})
+	}
+	return cells
+}
+
+// docxRowHeights は各行の最小の高さを返す。1行だけのSetCellがあればその高さ、なければデフォルト
+func (s Section) docxRowHeights() []float64 {
+	heights := make([]float64, s.rowNum)
+	for _, c := range s.cells {
+		if c.kind == singleCell && c.row_f-c.row_i == 1 && c.row_i < s.rowNum && c.rowH > heights[c.row_i] {
+			heights[c.row_i] = c.rowH
+		}
+	}
+	for i, h := range heights {
+		if h == 0 {
+			heights[i] = docxRowH
+		}
+	}
+	return heights
+}
+
+// writeDOCXTable は結合セルを含む表を書き出す。縦の結合は vMerge、横の結合は gridSpan で表す
+func writeDOCXTable(b *strings.Builder, widths, heights []float64, cells []docxCell, outer float64, jc string) {
+	// グリッドの占有状況
+	occ := make([][]int, len(heights))
+	for r := range occ {
+		occ[r] = make([]int, len(widths))
+		for c := range occ[r] {
+			occ[r][c] = -1
+		}
+	}
+	for k, cell := range cells {
+		if cell.row_i < 0 || cell.row_f > len(heights) || cell.gc_i < 0 || cell.gc_f > len(widths) || cell.row_i >= cell.row_f || cell.gc_i >= cell.gc_f {
+			fmt.Print("[DOCX] Invalid cell: out of range: ", cell.text, "\n")
+			continue
+		}
+		free := true
+		for r := cell.row_i; r < cell.row_f; r++ {
+			for c := cell.gc_i; c < cell.gc_f; c++ {
+				if occ[r][c] >= 0 {
+					free = false
+				}
+			}
+		}
+		if !free {
+			fmt.Print("[DOCX] Invalid cell: overlaps another cell: ", cell.text, "\n")
+			continue
+		}
+		for r := cell.row_i; r < cell.row_f; r++ {
+			for c := cell.gc_i; c < cell.gc_f; c++ {
+				occ[r][c] = k
+			}
+		}
+	}
+
+	total := 0.0
+	for _, w := range widths {
+		total += w
+	}
+	outerSz := int(math.Round(outer / 25.4 * 72 * 8)) // 1/8pt
+	innerSz := int(math.Round(0.1 / 25.4 * 72 * 8))
+	fmt.Fprintf(b, `<w:tbl><w:tblPr><w:tblW w:w="%d" w:type="dxa"/><w:jc w:val="%s"/>`, mmToTwip(total), jc)
+	fmt.Fprintf(b, `<w:tblBorders><w:top w:val="single" w:sz="%[1]d" w:space="0" w:color="000000"/><w:left w:val="single" w:sz="%[1]d" w:space="0" w:color="000000"/><w:bottom w:val="single" w:sz="%[1]d" w:space="0" w:color="000000"/><w:right w:val="single" w:sz="%[1]d" w:space="0" w:color="000000"/>`, outerSz)
+	fmt.Fprintf(b, `<w:insideH w:val="single" w:sz="%[1]d" w:space="0" w:color="000000"/><w:insideV w:val="single" w:sz="%[1]d" w:space="0" w:color="000000"/></w:tblBorders>`, innerSz)
+	// CT_TblPr は順序が決まっている（tblBorders → tblLayout → tblCellMar）
+	b.WriteString(`<w:tblLayout w:type="fixed"/><w:tblCellMar><w:top w:w="0" w:type="dxa"/><w:left w:w="28" w:type="dxa"/><w:bottom w:w="0" w:type="dxa"/><w:right w:w="28" w:type="dxa"/></w:tblCellMar></w:tblPr>`)
+	b.WriteString(`<w:tblGrid>`)
+	for _, w := range widths {
+		fmt.Fprintf(b, `<w:gridCol w:w="%d"/>`, mmToTwip(w))
+	}
+	b.WriteString(`</w:tblGrid>`)
+
+	for r, h := range heights {
+		fmt.Fprintf(b, `<w:tr><w:trPr><w:trHeight w:val="%d" w:hRule="atLeast"/></w:trPr>`, mmToTwip(h))
+		for gc := 0; gc < len(widths); {
+			k := occ[r][gc]
+			if k < 0 { // 空きグリッド
+				fmt.Fprintf(b, `<w:tc><w:tcPr><w:tcW w:w="%d" w:type="dxa"/></w:tcPr><w:p/></w:tc>`, mmToTwip(widths[gc]))
+				gc++
+				continue
+			}
+			cell := cells[k]
+			w := 0.0
+			for c := cell.gc_i; c < cell.gc_f; c++ {
+				w += widths[c]
+			}
+			fmt.Fprintf(b, `<w:tc><w:tcPr><w:tcW w:w="%d" w:type="dxa"/>`, mmToTwip(w))
+			if span := cell.gc_f - cell.gc_i; span > 1 {
+				fmt.Fprintf(b, `<w:gridSpan w:val="%d"/>`, span)
+			}
+			if cell.row_f-cell.row_i > 1 {
+				if r == cell.row_i {
+					b.WriteString(`<w:vMerge w:val="restart"/>`)
+				} else {
+					b.WriteString(`<w:vMerge/>`)
+				}
+			}
+			if cell.fill {
+				fmt.Fprintf(b, `<w:shd w:val="clear" w:color="auto" w:fill="%s"/>`, docxFillColor)
+			}
+			if cell.vertical {
+				b.WriteString(`<w:textDirection w:val="tbRlV"/>`)
+			}
+			b.WriteString(`<w:vAlign w:val="center"/></w:tcPr>`)
+			fmt.Fprintf(b, `<w:p><w:pPr><w:jc w:val="%s"/></w:pPr>`, docxAlign(cell.align))
+			if r == cell.row_i {
+				writeDOCXRuns(b, cell.text, cell.fontSize)
+			}
+			b.WriteString(`</w:p></w:tc>`)
+			gc = cell.gc_f
+		}
+		b.WriteString(`</w:tr>`)
+	}
+	b.WriteString(`</w:tbl>`)
+}
+
+// writeDOCXRuns はテキストを改行ごとに <w:br/> で区切ったランとして書き出す
+func writeDOCXRuns(b *strings.Builder, text string, fontSize float64) {
+	if text == "" {
+		return
+	}
+	sz := ptToHalfPt(fontSize)
+	fmt.Fprintf(b, `<w:r><w:rPr><w:sz w:val="%d"/><w:szCs w:val="%d"/></w:rPr>`, sz, sz)
+	for i, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if i != 0 {
+			b.WriteString(`<w:br/>`)
+		}
+		b.WriteString(`<w:t xml:space="preserve">`)
+		xml.EscapeText(b, []byte(line))
+		b.WriteString(`</w:t>`)
+	}
+	b.WriteString(`</w:r>`)
+}
+
+// 表の間隔（PDFの offsetH と同じ2mm）
+func writeDOCXSpacer(b *strings.Builder) {
+	fmt.Fprintf(b, `<w:p><w:pPr><w:spacing w:after="0" w:line="%d" w:lineRule="exact"/></w:pPr></w:p>`, mmToTwip(2.0))
+}
+
+func docxAlign(align string) string {
+	switch align {
+	case "C":
+		return "center"
+	case "R":
+		return "right"
+	}
+	return "left"
+}
@@ -0,0 +1,262 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// feedDateFormat: Indeed のフィードで使われる日付の形式（RFC 1123、GMT）
+const feedDateFormat = "Mon, 02 Jan 2006 15:04:05 GMT"
+
+// feedURLID: 求人ページのURLの中で求人番号に置き換える文字列
+const feedURLID = "{id}"
+
+// cdata: CDATA セクションとして書き出す値
+type cdata struct {
+	Text string `xml:",cdata"`
+}
+
+func newCDATA(s string) *cdata {
+	s = xmlSafe(strings.TrimSpace(s))
+	if s == "" {
+		return nil
+	}
+	return &cdata{Text: s}
+}
+
+func (c *cdata) String() string {
+	if c == nil {
+		return ""
+	}
+	return c.Text
+}
+
+// JobFeed: Indeed 形式の求人フィード（<source> 要素）
+type JobFeed struct {
+	XMLName       xml.Name  `xml:"source"`
+	Publisher     string    `xml:"publisher"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Jobs          []FeedJob `xml:"job"`
+}
+
+// FeedJob: フィードの1件分の求人（<job> 要素）
+type FeedJob struct {
+	Title           *cdata `xml:"title"`
+	Date            *cdata `xml:"date"`
+	ReferenceNumber *cdata `xml:"referencenumber"`
+	URL             *cdata `xml:"url"`
+	Company         *cdata `xml:"company"`
+	City            *cdata `xml:"city"`
+	State           *cdata `xml:"state"`
+	Country         *cdata `xml:"country"`
+	StreetAddress   *cdata `xml:"streetaddress,omitempty"`
+	Description     *cdata `xml:"description"`
+	Salary          *cdata `xml:"salary,omitempty"`
+	JobType         *cdata `xml:"jobtype,omitempty"`
+	Category        *cdata `xml:"category,omitempty"`
+}
+
+// schema.org の employmentType と Indeed の jobtype の対応
+var feedJobTypes = map[string]string{
+	"FULL_TIME":  "fulltime",
+	"PART_TIME":  "parttime",
+	"CONTRACTOR": "contract",
+	"TEMPORARY":  "temporary",
+	"INTERN":     "internship",
+}
+
+// FeedSource: フィードにまとめる1ファイル分の求人票
+type FeedSource struct {
+	Name     string
+	Postings [][][]string
+}
+
+// feedJobURL は求人ページのURLの「{id}」を求人番号に置き換える
+func feedJobURL(jobURL, id string) string {
+	return strings.ReplaceAll(strings.TrimSpace(jobURL), feedURLID, url.PathEscape(strings.TrimSpace(id)))
+}
+
+// NewFeedJob は求人票の内容からフィードの1件を作る。jobURL は求人ページのURL（「{id}」は求人番号に置き換える）。
+// 求人票の項目から変換できなかったものは warnings に入れる
+func NewFeedJob(p JobPosting, sections []PostingSection, jobURL string, now time.Time) (FeedJob, []string) {
+	var warnings []string
+	addr := parseAddress(p.Location)
+	job := FeedJob{
+		Title:           newCDATA(p.JobTitle),
+		Date:            newCDATA(now.UTC().Format(feedDateFormat)),
+		ReferenceNumber: newCDATA(p.ID),
+		Company:         newCDATA(p.CompanyName),
+		City:            newCDATA(addr.AddressLocality),
+		State:           newCDATA(addr.AddressRegion),
+		Country:         newCDATA(addr.AddressCountry),
+		StreetAddress:   newCDATA(addr.StreetAddress),
+		Description:     newCDATA(descriptionHTML(sections)),
+		Salary:          newCDATA(feedSalary(p)),
+		Category:        newCDATA(p.JobCategory),
+	}
+
+	// url は求人ごとのページのURLにする（会社のホームページは使わない）
+	if u := feedJobURL(jobURL, p.ID); urlPattern.MatchString(u) {
+		job.URL = newCDATA(u)
+	} else if u != "" {
+		warnings = append(warnings, fmt.Sprintf("求人ページのURL「%s」がURLではないため url を出力しません", u))
+	}
+
+	var types []string
+	for _, t := range employmentTypes {
+		if strings.Contains(p.EmploymentType, t.word) && !contains(types, feedJobTypes[t.ld]) {
+			types = append(types, feedJobTypes[t.ld])
+		}
+	}
+	if len(types) > 0 {
+		job.JobType = newCDATA(strings.Join(types, ", "))
+	} else if strings.TrimSpace(p.EmploymentType) != "" {
+		warnings = append(warnings, fmt.Sprintf("雇用形態「%s」を jobtype に変換できません", p.EmploymentType))
+	}
+
+	if p.Location != "" && addr.AddressRegion == "" {
+		warnings = append(warnings, fmt.Sprintf("勤務地「%s」から都道府県を読み取れません", p.Location))
+	}
+	return job, warnings
+}
+
+// feedSalary は給与形態・下限・上限・備考を1行の給与表記にする
+func feedSalary(p JobPosting) string {
+	var amount string
+	min, max := strings.TrimSpace(p.SalaryMin), strings.TrimSpace(p.SalaryMax)
+	switch {
+	case min != "" && max != "":
+		amount = min + "〜" + max
+	case min != "":
+		amount = min + "〜"
+	case max != "":
+		amount = "〜" + max
+	}
+	var parts []string
+	for _, s := range []string{p.SalaryType, amount, p.SalaryNote} {
+		if s = strings.Join(strings.Fields(s), " "); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// Validate はフィードの必須要素のうち値のないものを返す
+func (j FeedJob) Validate() []string {
+	var missing []string
+	required := []struct {
+		name  string
+		value *cdata
+	}{
+		{"title（職種）", j.Title},
+		{"date", j.Date},
+		{"referencenumber（求人番号）", j.ReferenceNumber},
+		{"url（求人ページのURL）", j.URL},
+		{"company（会社名）", j.Company},
+		{"city（勤務地の市区町村）", j.City},
+		{"state（勤務地の都道府県）", j.State},
+		{"country", j.Country},
+		{"description", j.Description},
+	}
+	for _, r := range required {
+		if r.value.String() == "" {
+			missing = append(missing, r.name)
+		}
+	}
+	return missing
+}
+
+// xmlSafe はXML 1.0で使えない制御文字を取り除く
+func xmlSafe(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\t' || r == '\n' || r == '\r':
+			return r
+		case r < 0x20, r == 0xFFFE, r == 0xFFFF, r >= 0xD800 && r <= 0xDFFF:
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// checkFeed は書き出したフィードを読み直し、構造と必須要素・求人番号の重複を確認する
+func checkFeed(data []byte) error {
+	var feed JobFeed
+	if err := xml.Unmarshal(data, &feed); err != nil {
+		return fmt.Errorf("フィードのXMLが不正です: %w", err)
+	}
+	refs := map[string]int{}
+	for i, job := range feed.Jobs {
+		if missing := job.Validate(); len(missing) > 0 {
+			return fmt.Errorf("%d件目の求人に必須要素 %s がありません", i+1, strings.Join(missing, "、"))
+		}
+		if _, err := time.Parse(feedDateFormat, job.Date.String()); err != nil {
+			return fmt.Errorf("%d件目の求人の date が不正です: %w", i+1, err)
+		}
+		if j, ok := refs[job.ReferenceNumber.String()]; ok {
+			return fmt.Errorf("%d件目と%d件目の求人番号「%s」が重複しています", j+1, i+1, job.ReferenceNumber.String())
+		}
+		refs[job.ReferenceNumber.String()] = i
+	}
+	return nil
+}
+
+// WriteJobFeed はバッチ全体の求人票を1つのIndeed形式のXMLフィードとして書き出す。
+// jobURL は求人ページのURL（「{id}」は求人番号に置き換える）。
+// 必須要素が欠けている求人票は出力せず、その理由を warnings に入れる
+func WriteJobFeed(w io.Writer, sources []FeedSource, jobURL string, now time.Time) ([]string, error) {
+	var warnings []string
+	count := 0
+	for _, src := range sources {
+		count += len(src.Postings)
+	}
+	if strings.TrimSpace(jobURL) == "" {
+		warnings = append(warnings, "求人ページのURLが指定されていないため、フィードに求人を含められません")
+	} else if !strings.Contains(jobURL, feedURLID) && count > 1 {
+		warnings = append(warnings, fmt.Sprintf("求人ページのURLに %s がないため、すべての求人が同じURLになります", feedURLID))
+	}
+	feed := JobFeed{
+		Publisher:     agencyName,
+		LastBuildDate: now.UTC().Format(feedDateFormat),
+	}
+	refs := map[string]string{}
+	for _, src := range sources {
+		for i, tableData := range src.Postings {
+			where := fmt.Sprintf("%s %d枚目", src.Name, i+1)
+			job, ws := NewFeedJob(NewJobPosting(tableData), PostingSections(tableData), jobURL, now)
+			for _, warning := range ws {
+				warnings = append(warnings, where+": "+warning)
+			}
+			if missing := job.Validate(); len(missing) > 0 {
+				warnings = append(warnings, fmt.Sprintf("%s: 必須要素 %s がないためフィードに含めません", where, strings.Join(missing, "、")))
+				continue
+			}
+			ref := job.ReferenceNumber.String()
+			if prev, ok := refs[ref]; ok {
+				warnings = append(warnings, fmt.Sprintf("%s: 求人番号「%s」が %s と重複しているためフィードに含めません", where, ref, prev))
+				continue
+			}
+			refs[ref] = where
+			feed.Jobs = append(feed.Jobs, job)
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(feed); err != nil {
+		return warnings, err
+	}
+	buf.WriteString("\n")
+	if err := checkFeed(buf.Bytes()); err != nil {
+		return warnings, err
+	}
+	_, err := w.Write(buf.Bytes())
+	return warnings, err
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"io"
 	"os"
+
+	"golang.org/x/image/font/opentype"
 )
 
 //go:embed fonts/*
@@ -37,3 +39,16 @@ func (a *App) loadFont() (*os.File, string, error) {
 	// 呼び出し元で明示的に Close & Remove すること
 	return tmpFontFile, tmpFontFile.Name(), nil
 }
+
+// parseFont は埋め込みフォントを画像描画用に読み込む
+func parseFont() (*opentype.Font, error) {
+	data, err := fontAssets.ReadFile("fonts/ipaexg.ttf")
+	if err != nil {
+		return nil, fmt.Errorf("フォントの読み込みに失敗: %w", err)
+	}
+	f, err := opentype.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("フォントの解析に失敗: %w", err)
+	}
+	return f, nil
+}
@@ -0,0 +1,118 @@
+package internal
+
+import (
+	"html/template"
+	"io"
+)
+
+// htmlPosting: HTMLテンプレートに渡す1シート分のデータ
+type htmlPosting struct {
+	IDLabel  string
+	ID       string
+	Sections []PostingSection
+	Appendix string
+}
+
+var htmlTemplate = template.Must(template.New("posting").Parse(`<!DOCTYPE html>
+<html lang="ja">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>求人票</title>
+<style>
+.job-posting { max-width: 840px; margin: 0 auto 32px; padding: 0 12px; font-family: "IPAexGothic", "Hiragino Sans", "Yu Gothic", sans-serif; font-size: 15px; line-height: 1.7; color: #222; }
+.job-posting header { display: flex; flex-wrap: wrap; justify-content: space-between; align-items: baseline; border-bottom: 2px solid #99ccff; margin-bottom: 16px; }
+.job-posting h1 { font-size: 1.6em; margin: 0.4em 0; }
+.job-posting .posting-id, .job-posting .agency { margin: 0; font-size: 0.85em; color: #555; }
+.job-posting h2 { font-size: 1.15em; margin: 1.2em 0 0.4em; padding: 2px 8px; background: #99ccff; }
+.job-posting dl { margin: 0; border-top: 1px solid #ccc; }
+.job-posting .item { display: grid; grid-template-columns: 10em 1fr; border-bottom: 1px solid #ccc; }
+.job-posting dt { padding: 6px 8px; background: #eef6ff; font-weight: bold; }
+.job-posting dd { margin: 0; padding: 6px 8px; white-space: pre-wrap; word-break: break-word; }
+.job-posting dd + dd { grid-column: 2; padding-top: 0; }
+.job-posting .appendix { margin-top: 16px; font-size: 0.85em; white-space: pre-wrap; }
+@media (max-width: 600px) {
+  .job-posting .item { grid-template-columns: 1fr; }
+  .job-posting dd + dd { grid-column: 1; }
+}
+</style>
+</head>
+<body>
+{{- range .}}
+<article class="job-posting">
+<header>
+<h1>求人票</h1>
+<p class="agency">` + agencyName + `</p>
+{{- if .ID}}
+<p class="posting-id">{{.IDLabel}} {{.ID}}</p>
+{{- end}}
+</header>
+{{- range .Sections}}
+<section>
+{{- if .Title}}
+<h2>{{.Title}}</h2>
+{{- end}}
+<dl>
+{{- range .Items}}
+<div class="item">
+<dt>{{.Label}}</dt>
+{{- range .Values}}
+<dd>{{.}}</dd>
+{{- end}}
+</div>
+{{- end}}
+</dl>
+</section>
+{{- end}}
+{{- if .Appendix}}
+<p class="appendix">{{.Appendix}}</p>
+{{- end}}
+</article>
+{{- end}}
+</body>
+</html>
+`))
+
+// newHTMLPosting はtableDataからテンプレート用のデータを作る。値のない項目は省略する
+func newHTMLPosting(tableData [][]string) htmlPosting {
+	p := htmlPosting{
+		IDLabel:  idSection.cells[0].src.Value(tableData),
+		ID:       idSection.cells[1].src.Value(tableData),
+		Appendix: appendixRef.Value(tableData),
+	}
+	for _, s := range PostingSections(tableData) {
+		var items []PostingItem
+		for _, item := range s.Items {
+			item.Values = nonEmpty(item.Values)
+			if item.Label == "" && len(item.Values) == 0 {
+				continue
+			}
+			items = append(items, item)
+		}
+		if len(items) == 0 {
+			continue
+		}
+		s.Items = items
+		p.Sections = append(p.Sections, s)
+	}
+	return p
+}
+
+func nonEmpty(values []string) []string {
+	var out []string
+	for _, v := range values {
+		if v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// WritePostingHTML はシートごとの求人票を1つのHTML文書として書き出す
+func WritePostingHTML(w io.Writer, postings [][][]string) error {
+	var data []htmlPosting
+	for _, tableData := range postings {
+		data = append(data, newHTMLPosting(tableData))
+	}
+	return htmlTemplate.Execute(w, data)
+}
@@ -0,0 +1,364 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JobPostingLD: schema.org の JobPosting（Google for Jobs 用の構造化データ）
+type JobPostingLD struct {
+	Context            string           `json:"@context"`
+	Type               string           `json:"@type"`
+	Title              string           `json:"title"`
+	Description        string           `json:"description"`
+	Identifier         *PropertyValueLD `json:"identifier,omitempty"`
+	DatePosted         string           `json:"datePosted"`
+	ValidThrough       string           `json:"validThrough,omitempty"`
+	EmploymentType     []string         `json:"employmentType,omitempty"`
+	HiringOrganization *OrganizationLD  `json:"hiringOrganization,omitempty"`
+	JobLocation        *PlaceLD         `json:"jobLocation,omitempty"`
+	BaseSalary         *MonetaryAmount  `json:"baseSalary,omitempty"`
+}
+
+type PropertyValueLD struct {
+	Type  string `json:"@type"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type OrganizationLD struct {
+	Type   string `json:"@type"`
+	Name   string `json:"name"`
+	SameAs string `json:"sameAs,omitempty"`
+}
+
+type PlaceLD struct {
+	Type    string          `json:"@type"`
+	Address PostalAddressLD `json:"address"`
+}
+
+type PostalAddressLD struct {
+	Type            string `json:"@type"`
+	StreetAddress   string `json:"streetAddress,omitempty"`
+	AddressLocality string `json:"addressLocality,omitempty"`
+	AddressRegion   string `json:"addressRegion,omitempty"`
+	AddressCountry  string `json:"addressCountry"`
+}
+
+type MonetaryAmount struct {
+	Type     string            `json:"@type"`
+	Currency string            `json:"currency"`
+	Value    QuantitativeValue `json:"value"`
+}
+
+type QuantitativeValue struct {
+	Type     string  `json:"@type"`
+	Value    float64 `json:"value,omitempty"`
+	MinValue float64 `json:"minValue,omitempty"`
+	MaxValue float64 `json:"maxValue,omitempty"`
+	UnitText string  `json:"unitText"`
+}
+
+// 雇用形態の表記と schema.org の employmentType の対応（先に一致したものを優先）
+var employmentTypes = []struct {
+	word string
+	ld   string
+}{
+	{"正社員", "FULL_TIME"},
+	{"正職員", "FULL_TIME"},
+	{"契約社員", "CONTRACTOR"},
+	{"業務委託", "CONTRACTOR"},
+	{"派遣", "TEMPORARY"},
+	{"アルバイト", "PART_TIME"},
+	{"パート", "PART_TIME"},
+	{"インターン", "INTERN"},
+}
+
+// 給与形態と unitText の対応
+var salaryUnits = []struct {
+	word string
+	unit string
+}{
+	{"時給", "HOUR"},
+	{"日給", "DAY"},
+	{"週給", "WEEK"},
+	{"月給", "MONTH"},
+	{"月収", "MONTH"},
+	{"年俸", "YEAR"},
+	{"年収", "YEAR"},
+}
+
+var prefecturePattern = regexp.MustCompile(`^(東京都|北海道|京都府|大阪府|[^\s都道府県]{2,3}県)`)
+var localityPattern = regexp.MustCompile(`^(.+?[市区町村])`)
+var urlPattern = regexp.MustCompile(`^https?://\S+$`)
+
+// NewJobPostingLD は求人票の内容から JobPosting の構造化データを作る。
+// 求人票の項目から変換できなかったものは warnings に入れる
+func NewJobPostingLD(p JobPosting, sections []PostingSection, now time.Time) (JobPostingLD, []string) {
+	var warnings []string
+	ld := JobPostingLD{
+		Context:     "https://schema.org/",
+		Type:        "JobPosting",
+		Title:       strings.TrimSpace(p.JobTitle),
+		Description: descriptionHTML(sections),
+		DatePosted:  now.Format("2006-01-02"),
+	}
+	warnings = append(warnings, "datePosted は求人票にないため変換日を使用します")
+
+	if p.ID != "" {
+		ld.Identifier = &PropertyValueLD{Type: "PropertyValue", Name: agencyName, Value: p.ID}
+	}
+
+	if p.Deadline != "" {
+		if d, ok := parsePostingDate(p.Deadline); ok {
+			ld.ValidThrough = d.Format("2006-01-02") + "T23:59"
+		} else {
+			warnings = append(warnings, fmt.Sprintf("応募締切「%s」を日付として読み取れません", p.Deadline))
+		}
+	} else {
+		warnings = append(warnings, "応募締切がないため validThrough を出力しません")
+	}
+
+	if p.EmploymentType != "" {
+		for _, t := range employmentTypes {
+			if strings.Contains(p.EmploymentType, t.word) && !contains(ld.EmploymentType, t.ld) {
+				ld.EmploymentType = append(ld.EmploymentType, t.ld)
+			}
+		}
+		if len(ld.EmploymentType) == 0 {
+			ld.EmploymentType = []string{"OTHER"}
+			warnings = append(warnings, fmt.Sprintf("雇用形態「%s」を対応付けできないため OTHER とします", p.EmploymentType))
+		}
+	}
+
+	if name := strings.TrimSpace(p.CompanyName); name != "" {
+		ld.HiringOrganization = &OrganizationLD{Type: "Organization", Name: name}
+		if site := strings.TrimSpace(p.Website); site != "" {
+			if urlPattern.MatchString(site) {
+				ld.HiringOrganization.SameAs = site
+			} else {
+				warnings = append(warnings, fmt.Sprintf("ホームページ「%s」がURLではないため sameAs を出力しません", site))
+			}
+		}
+	}
+
+	if location := strings.TrimSpace(p.Location); location != "" {
+		ld.JobLocation = &PlaceLD{Type: "Place", Address: parseAddress(location)}
+		if ld.JobLocation.Address.AddressRegion == "" {
+			warnings = append(warnings, fmt.Sprintf("勤務地「%s」から都道府県を読み取れません", location))
+		}
+	}
+
+	if p.SalaryMin != "" || p.SalaryMax != "" {
+		salary, warning := parseSalary(p)
+		ld.BaseSalary = salary
+		if warning != "" {
+			warnings = append(warnings, warning)
+		}
+	} else {
+		warnings = append(warnings, "給与がないため baseSalary を出力しません")
+	}
+
+	return ld, warnings
+}
+
+// Validate は Google for Jobs の必須プロパティのうち欠けているものを返す
+func (ld JobPostingLD) Validate() []string {
+	var missing []string
+	if ld.Title == "" {
+		missing = append(missing, "title（職種）")
+	}
+	if ld.Description == "" {
+		missing = append(missing, "description")
+	}
+	if ld.DatePosted == "" {
+		missing = append(missing, "datePosted")
+	}
+	if ld.HiringOrganization == nil {
+		missing = append(missing, "hiringOrganization（会社名）")
+	}
+	if ld.JobLocation == nil {
+		missing = append(missing, "jobLocation（勤務地）")
+	}
+	return missing
+}
+
+// descriptionHTML は各表の項目を description 用のHTMLにする
+func descriptionHTML(sections []PostingSection) string {
+	var b strings.Builder
+	for _, s := range sections {
+		for _, item := range s.Items {
+			values := nonEmpty(item.Values)
+			if len(values) == 0 {
+				continue
+			}
+			b.WriteString("<p>")
+			if item.Label != "" {
+				b.WriteString("<strong>" + html.EscapeString(item.Label) + "</strong><br>")
+			}
+			for i, v := range values {
+				if i != 0 {
+					b.WriteString("<br>")
+				}
+				b.WriteString(strings.ReplaceAll(html.EscapeString(v), "\n", "<br>"))
+			}
+			b.WriteString("</p>")
+		}
+	}
+	return b.String()
+}
+
+// parseAddress は勤務地を都道府県・市区町村・それ以降に分ける
+func parseAddress(location string) PostalAddressLD {
+	addr := PostalAddressLD{Type: "PostalAddress", AddressCountry: "JP"}
+	rest := strings.SplitN(location, "\n", 2)[0]
+	if m := prefecturePattern.FindString(rest); m != "" {
+		addr.AddressRegion = m
+		rest = strings.TrimPrefix(rest, m)
+	}
+	if m := localityPattern.FindString(rest); m != "" {
+		addr.AddressLocality = m
+		rest = strings.TrimPrefix(rest, m)
+	}
+	addr.StreetAddress = strings.TrimSpace(rest)
+	return addr
+}
+
+// parseSalary は給与の下限・上限と給与形態から baseSalary を作る
+func parseSalary(p JobPosting) (*MonetaryAmount, string) {
+	unit := ""
+	for _, u := range salaryUnits {
+		if strings.Contains(p.SalaryType, u.word) || strings.Contains(p.SalaryMin, u.word) {
+			unit = u.unit
+			break
+		}
+	}
+	if unit == "" {
+		return nil, fmt.Sprintf("給与形態「%s」から単位（時給・月給・年収など）を読み取れないため baseSalary を出力しません", p.SalaryType)
+	}
+
+	value := QuantitativeValue{Type: "QuantitativeValue", UnitText: unit}
+	min, minOK := parseYen(p.SalaryMin)
+	max, maxOK := parseYen(p.SalaryMax)
+	switch {
+	case minOK && maxOK:
+		value.MinValue, value.MaxValue = min, max
+	case minOK:
+		value.Value = min
+	case maxOK:
+		value.MaxValue = max
+	default:
+		return nil, fmt.Sprintf("給与「%s〜%s」を金額として読み取れないため baseSalary を出力しません", p.SalaryMin, p.SalaryMax)
+	}
+	return &MonetaryAmount{Type: "MonetaryAmount", Currency: "JPY", Value: value}, ""
+}
+
+var yenPattern = regexp.MustCompile(`([0-9０-９][0-9０-９,，.]*)\s*(万)?`)
+
+// parseYen は「¥250,000」「25万円」などを円の金額にする
+func parseYen(s string) (float64, bool) {
+	m := yenPattern.FindStringSubmatch(toHalfWidth(s))
+	if m == nil {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(strings.ReplaceAll(m[1], ",", ""), 64)
+	if err != nil {
+		return 0, false
+	}
+	if m[2] == "万" {
+		v *= 10000
+	}
+	return v, true
+}
+
+// toHalfWidth は全角の数字と記号を半角にする
+func toHalfWidth(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '！' && r <= '～' {
+			return r - '！' + '!'
+		}
+		if r == '　' {
+			return ' '
+		}
+		return r
+	}, s)
+}
+
+var (
+	isoDatePattern   = regexp.MustCompile(`(\d{4})[/\-.](\d{1,2})[/\-.](\d{1,2})`)
+	kanjiDatePattern = regexp.MustCompile(`(\d{4})年\s*(\d{1,2})月\s*(\d{1,2})日`)
+	eraDatePattern   = regexp.MustCompile(`(令和|平成|昭和|R|H|S)\s*(\d{1,2}|元)\s*[年./]\s*(\d{1,2})\s*[月./]\s*(\d{1,2})`)
+)
+
+// 元号の開始年（元年の西暦）
+var eraStartYears = map[string]int{
+	"令和": 2019, "R": 2019,
+	"平成": 1989, "H": 1989,
+	"昭和": 1926, "S": 1926,
+}
+
+// parsePostingDate は「2025/03/31」「2025年3月31日」「令和7年3月31日」などを日付にする
+func parsePostingDate(s string) (time.Time, bool) {
+	s = toHalfWidth(s)
+	if m := isoDatePattern.FindStringSubmatch(s); m != nil {
+		return makeDate(m[1], m[2], m[3])
+	}
+	if m := kanjiDatePattern.FindStringSubmatch(s); m != nil {
+		return makeDate(m[1], m[2], m[3])
+	}
+	if m := eraDatePattern.FindStringSubmatch(s); m != nil {
+		year := 1
+		if m[2] != "元" {
+			year, _ = strconv.Atoi(m[2])
+		}
+		return makeDate(strconv.Itoa(eraStartYears[m[1]]+year-1), m[3], m[4])
+	}
+	return time.Time{}, false
+}
+
+func makeDate(year, month, day string) (time.Time, bool) {
+	y, _ := strconv.Atoi(year)
+	m, _ := strconv.Atoi(month)
+	d, _ := strconv.Atoi(day)
+	t := time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.Local)
+	if t.Year() != y || int(t.Month()) != m || t.Day() != d { // 存在しない日付
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// WritePostingJSONLD はシートごとの JobPosting 構造化データをJSON配列として書き出す。
+// 必須プロパティが欠けている求人票は出力せず、その理由を warnings に入れる
+func WritePostingJSONLD(w io.Writer, postings [][][]string, now time.Time) ([]string, error) {
+	var warnings []string
+	lds := []JobPostingLD{}
+	for i, tableData := range postings {
+		ld, ws := NewJobPostingLD(NewJobPosting(tableData), PostingSections(tableData), now)
+		for _, warning := range ws {
+			warnings = append(warnings, fmt.Sprintf("%d枚目: %s", i+1, warning))
+		}
+		if missing := ld.Validate(); len(missing) > 0 {
+			warnings = append(warnings, fmt.Sprintf("%d枚目: 必須プロパティ %s がないため出力しません", i+1, strings.Join(missing, "、")))
+			continue
+		}
+		lds = append(lds, ld)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return warnings, enc.Encode(lds)
+}
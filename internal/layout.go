@@ -0,0 +1,443 @@
+package internal
+
+import (
+	"github.com/jung-kurt/gofpdf"
+)
+
+// 求人票の発行元（右上に表示する会社名）
+const agencyName = "株式会社アーリー・バード・エージェント"
+
+// CellRef: tableData上のセル位置（tableData[Row][Col]）
+type CellRef struct {
+	Row int
+	Col int
+}
+
+func at(row, col int) CellRef {
+	return CellRef{Row: row, Col: col}
+}
+
+// Value はtableDataからセルの値を取り出す。範囲外の場合は空文字を返す
+func (r CellRef) Value(tableData [][]string) string {
+	if r.Row < 0 || r.Row >= len(tableData) {
+		return ""
+	}
+	if r.Col < 0 || r.Col >= len(tableData[r.Row]) {
+		return ""
+	}
+	return tableData[r.Row][r.Col]
+}
+
+type cellKind int
+
+const (
+	singleCell   cellKind = iota // SetCell
+	multiRowCell                 // SetMultiRowCell
+	titledCell                   // SetCellWithTitle
+)
+
+// LayoutCell: 表の中の1セルの配置と、そのセルに入れるデータの参照
+type LayoutCell struct {
+	kind       cellKind
+	col_i      int     // 開始列インデックス
+	row_i      int     // 開始行インデックス
+	col_f      int     // 終了列インデックス
+	row_f      int     // 終了行インデックス
+	src        CellRef // 表示するデータ
+	label      CellRef // 対応するラベルのデータ（ラベルセル自身は src と同じ）
+	align      string  // テキストの配置
+	fill       bool    // 塗りつぶしフラグ（ラベルセル）
+	fontSize   float64 // フォントサイズ（負の値で表のデフォルト）
+	lineWidth  float64 // 線の太さ（SetCellのみ）
+	rowH       float64 // 行の高さ（SetCellのみ）
+	breakLines bool    // ページをまたいで分割するか（SetMultiRowCellのみ）
+}
+
+// Section: 求人票の1つの表。セルは描画順に並べる（複数行セルの高さを先に決めてからラベルを置くため）
+type Section struct {
+	title   *CellRef // 左側の縦書きタイトル（nilの場合はタイトルなし）
+	rowNum  int      // 行数
+	rowH    float64  // 行の高さ
+	outline bool     // 外枠を描画するか
+	cells   []LayoutCell
+}
+
+// ラベルセル（塗りつぶし・中央揃え）
+func labelCell(col_i, row_i, col_f, row_f int, src CellRef, rowH float64) LayoutCell {
+	return LayoutCell{kind: singleCell, col_i: col_i, row_i: row_i, col_f: col_f, row_f: row_f, src: src, label: src, align: "C", fill: true, fontSize: -1.0, lineWidth: 0.1, rowH: rowH}
+}
+
+// 値セル（1行）
+func valueCell(col_i, row_i, col_f, row_f int, src, label CellRef, fontSize, rowH float64) LayoutCell {
+	return LayoutCell{kind: singleCell, col_i: col_i, row_i: row_i, col_f: col_f, row_f: row_f, src: src, label: label, align: "L", fontSize: fontSize, lineWidth: 0.1, rowH: rowH}
+}
+
+// 値セル（複数行）
+func multiCell(col_i, row_i, col_f, row_f int, src, label CellRef, breakLines bool) LayoutCell {
+	return LayoutCell{kind: multiRowCell, col_i: col_i, row_i: row_i, col_f: col_f, row_f: row_f, src: src, label: label, align: "L", fontSize: -1.0, breakLines: breakLines}
+}
+
+// ラベルセル（複数行）
+func multiLabelCell(col_i, row_i, col_f, row_f int, src CellRef, breakLines bool) LayoutCell {
+	return LayoutCell{kind: multiRowCell, col_i: col_i, row_i: row_i, col_f: col_f, row_f: row_f, src: src, label: src, align: "C", fill: true, fontSize: -1.0, breakLines: breakLines}
+}
+
+// タイトル付きラベルセル
+func titledLabelCell(col_i, row_i, col_f, row_f int, src CellRef) LayoutCell {
+	return LayoutCell{kind: titledCell, col_i: col_i, row_i: row_i, col_f: col_f, row_f: row_f, src: src, label: src, align: "C", fill: true, fontSize: -1.0}
+}
+
+func refPtr(r CellRef) *CellRef {
+	return &r
+}
+
+// 求人番号（タイトル右下の小さな表）
+var idSection = Section{
+	rowNum: 1,
+	rowH:   2.5,
+	cells: []LayoutCell{
+		{kind: singleCell, col_i: 7, row_i: 0, col_f: 8, row_f: 1, src: at(2, 24), label: at(2, 24), align: "C", fill: true, fontSize: 5.0, lineWidth: 0.3, rowH: 2.5},
+		{kind: singleCell, col_i: 8, row_i: 0, col_f: 9, row_f: 1, src: at(2, 27), label: at(2, 24), align: "C", fontSize: 5.0, lineWidth: 0.3, rowH: 2.5},
+	},
+}
+
+// 求人票本体の表（TABLE A〜F）
+var postingSections = []Section{
+	// TABLE A
+	{
+		title:   refPtr(at(3, 0)),
+		rowNum:  8,
+		rowH:    4.0,
+		outline: true,
+		cells: []LayoutCell{
+			valueCell(1, 0, 6, 1, at(3, 2), at(3, 1), 5.0, 2.5),
+			valueCell(1, 1, 6, 2, at(4, 2), at(3, 1), 10.0, 7.0),
+			labelCell(0, 0, 1, 2, at(3, 1), 4.0),
+
+			labelCell(6, 0, 7, 2, at(3, 21), 4.0),
+			multiCell(7, 0, 9, 2, at(3, 24), at(3, 21), false),
+
+			labelCell(0, 2, 1, 3, at(5, 1), 4.0),
+			valueCell(1, 2, 3, 3, at(5, 2), at(5, 1), -1.0, 4.0),
+			labelCell(3, 2, 4, 3, at(5, 10), 4.0),
+			valueCell(4, 2, 6, 3, at(5, 13), at(5, 10), -1.0, 4.0),
+			labelCell(6, 2, 7, 3, at(5, 21), 4.0),
+			valueCell(7, 2, 9, 3, at(5, 24), at(5, 21), -1.0, 4.0),
+
+			labelCell(0, 3, 1, 4, at(6, 1), 4.0),
+			valueCell(1, 3, 3, 4, at(6, 2), at(6, 1), -1.0, 4.0),
+			labelCell(3, 3, 4, 4, at(6, 10), 4.0),
+			valueCell(4, 3, 9, 4, at(6, 13), at(6, 10), -1.0, 4.0),
+
+			labelCell(0, 4, 1, 5, at(7, 1), 4.0),
+			valueCell(1, 4, 9, 5, at(7, 2), at(7, 1), -1.0, 4.0),
+
+			multiCell(1, 5, 9, 6, at(8, 2), at(8, 1), false),
+			labelCell(0, 5, 1, 6, at(8, 1), 4.0),
+
+			multiCell(1, 6, 9, 7, at(9, 2), at(9, 1), true),
+			labelCell(0, 6, 1, 7, at(9, 1), 4.0),
+
+			multiCell(1, 7, 9, 8, at(10, 2), at(10, 1), true),
+			labelCell(0, 7, 1, 8, at(10, 1), 4.0),
+		},
+	},
+	// TABLE B
+	{
+		title:   refPtr(at(12, 0)),
+		rowNum:  10,
+		rowH:    4.5,
+		outline: true,
+		cells: []LayoutCell{
+			labelCell(0, 0, 1, 1, at(12, 1), 4.5),
+			valueCell(1, 0, 9, 1, at(12, 2), at(12, 1), -1.0, 4.5),
+
+			multiCell(1, 1, 9, 2, at(13, 2), at(13, 1), true),
+			labelCell(0, 1, 1, 2, at(13, 1), 4.5),
+
+			labelCell(0, 2, 1, 4, at(14, 1), 9.0),
+			valueCell(1, 2, 3, 4, at(14, 2), at(14, 1), -1.0, 9.0),
+			labelCell(3, 2, 4, 3, at(14, 10), 4.5),
+			valueCell(4, 2, 6, 3, at(14, 13), at(14, 10), -1.0, 4.5),
+			labelCell(6, 2, 7, 3, at(14, 21), 4.5),
+			valueCell(7, 2, 9, 3, at(14, 24), at(14, 21), -1.0, 4.5),
+
+			labelCell(3, 3, 4, 4, at(15, 10), 4.5),
+			valueCell(4, 3, 9, 4, at(15, 13), at(15, 10), -1.0, 4.5),
+
+			multiCell(1, 4, 9, 5, at(16, 2), at(16, 1), false),
+			labelCell(0, 4, 1, 5, at(16, 1), 4.5),
+
+			multiCell(1, 5, 9, 6, at(17, 2), at(17, 1), false),
+			labelCell(0, 5, 1, 6, at(17, 1), 4.5),
+
+			multiCell(1, 6, 9, 7, at(18, 2), at(18, 1), false),
+			labelCell(0, 6, 1, 7, at(18, 1), 4.5),
+
+			labelCell(0, 7, 1, 8, at(19, 1), 4.5),
+			valueCell(1, 7, 3, 8, at(19, 2), at(19, 1), -1.0, 4.5),
+			labelCell(3, 7, 4, 8, at(19, 10), 4.5),
+			valueCell(4, 7, 9, 8, at(19, 13), at(19, 10), -1.0, 4.5),
+
+			labelCell(0, 8, 1, 9, at(20, 1), 4.5),
+			valueCell(1, 8, 3, 9, at(20, 2), at(20, 1), -1.0, 4.5),
+			labelCell(3, 8, 4, 9, at(20, 10), 4.5),
+			valueCell(4, 8, 6, 9, at(20, 13), at(20, 10), -1.0, 4.5),
+			labelCell(6, 8, 7, 9, at(20, 21), 4.5),
+			valueCell(7, 8, 9, 9, at(20, 24), at(20, 21), -1.0, 4.5),
+
+			multiCell(1, 9, 9, 10, at(21, 2), at(21, 1), true),
+			labelCell(0, 9, 1, 10, at(21, 1), 4.5),
+		},
+	},
+	// TABLE C
+	{
+		title:   refPtr(at(23, 0)),
+		rowNum:  2,
+		rowH:    4.5,
+		outline: true,
+		cells: []LayoutCell{
+			labelCell(0, 0, 1, 1, at(23, 1), 4.5),
+			valueCell(1, 0, 3, 1, at(23, 2), at(23, 1), -1.0, 4.5),
+			labelCell(3, 0, 4, 1, at(23, 10), 4.5),
+			valueCell(4, 0, 9, 1, at(23, 13), at(23, 10), -1.0, 4.5),
+
+			multiCell(1, 1, 9, 2, at(24, 2), at(24, 1), true),
+			multiLabelCell(0, 1, 1, 2, at(24, 1), true),
+		},
+	},
+	// TABLE D
+	{
+		title:   refPtr(at(26, 0)),
+		rowNum:  6,
+		rowH:    4.5,
+		outline: true,
+		cells: []LayoutCell{
+			labelCell(0, 0, 1, 1, at(26, 1), 4.5),
+			valueCell(1, 0, 3, 1, at(26, 2), at(26, 1), -1.0, 4.5),
+			labelCell(3, 0, 4, 1, at(26, 10), 4.5),
+			valueCell(4, 0, 6, 1, at(26, 13), at(26, 10), -1.0, 4.5),
+			labelCell(6, 0, 7, 1, at(26, 21), 4.5),
+			valueCell(7, 0, 9, 1, at(26, 24), at(26, 21), -1.0, 4.5),
+
+			labelCell(0, 1, 1, 2, at(27, 1), 4.5),
+			valueCell(1, 1, 3, 2, at(27, 2), at(27, 1), -1.0, 4.5),
+			labelCell(3, 1, 4, 2, at(27, 10), 4.5),
+			valueCell(4, 1, 6, 2, at(27, 13), at(27, 10), -1.0, 4.5),
+			labelCell(6, 1, 7, 2, at(27, 21), 4.5),
+			valueCell(7, 1, 9, 2, at(27, 24), at(27, 21), -1.0, 4.5),
+
+			multiCell(1, 2, 9, 3, at(28, 2), at(28, 1), true),
+			labelCell(0, 2, 1, 3, at(28, 1), 4.5),
+
+			multiCell(1, 3, 9, 4, at(29, 2), at(29, 1), true),
+			labelCell(0, 3, 1, 4, at(29, 1), 4.5),
+
+			multiCell(1, 4, 9, 5, at(30, 2), at(30, 1), true),
+			labelCell(0, 4, 1, 5, at(30, 1), 4.5),
+
+			multiCell(1, 5, 6, 6, at(31, 2), at(31, 1), true),
+			labelCell(0, 5, 1, 6, at(31, 1), 4.5),
+			labelCell(6, 5, 7, 6, at(31, 21), 4.5),
+			valueCell(7, 5, 9, 6, at(31, 24), at(31, 21), -1.0, 4.5),
+		},
+	},
+	// TABLE E
+	{
+		title:   refPtr(at(33, 0)),
+		rowNum:  3,
+		rowH:    4.5,
+		outline: true,
+		cells: []LayoutCell{
+			multiCell(1, 0, 6, 1, at(33, 2), at(33, 1), false),
+			labelCell(0, 0, 1, 1, at(33, 1), 4.5),
+			labelCell(6, 0, 7, 1, at(33, 21), 4.5),
+			valueCell(7, 0, 9, 1, at(33, 24), at(33, 21), -1.0, 4.5),
+
+			multiCell(1, 1, 9, 2, at(34, 2), at(34, 1), false),
+			labelCell(0, 1, 1, 2, at(34, 1), 4.5),
+
+			multiCell(1, 2, 9, 3, at(35, 2), at(35, 1), false),
+			labelCell(0, 2, 1, 3, at(35, 1), 4.5),
+		},
+	},
+	// TABLE F
+	{
+		rowNum: 1,
+		rowH:   4.5,
+		cells: []LayoutCell{
+			multiCell(1, 0, 9, 1, at(37, 2), at(37, 0), false),
+			titledLabelCell(0, 0, 1, 1, at(37, 0)),
+		},
+	},
+}
+
+// 付録（表の下の注記）
+var appendixRef = at(41, 0)
+
+// Fill は表にセクションのセルを配置する。rich は書式付きの文字列があるセル（複数行セルで書式どおりに描く）
+func (s Section) Fill(t *Table, tableData [][]string, rich map[CellRef][]TextRun) {
+	for _, c := range s.cells {
+		text := c.src.Value(tableData)
+		switch c.kind {
+		case singleCell:
+			t.SetCell(c.col_i, c.row_i, c.col_f, c.row_f, text, c.align, c.fill, c.fontSize, "", c.lineWidth, c.rowH)
+		case multiRowCell:
+			if runs, ok := rich[c.src]; ok && runsText(runs) == text {
+				t.SetMultiRowRichCell(c.col_i, c.row_i, c.col_f, c.row_f, runs, c.align, c.fill, c.fontSize, c.breakLines)
+				continue
+			}
+			t.SetMultiRowCell(c.col_i, c.row_i, c.col_f, c.row_f, text, c.align, c.fill, c.fontSize, c.breakLines)
+		case titledCell:
+			t.SetCellWithTitle(c.col_i, c.row_i, c.col_f, c.row_f, text, c.align, c.fill, c.fontSize)
+		}
+	}
+	if s.title != nil {
+		t.SetTitle(s.title.Value(tableData))
+	}
+}
+
+// PostingItem: ラベルとその値（HTMLなどの書き出し用）
+type PostingItem struct {
+	Label  string   `json:"label"`
+	Values []string `json:"values"`
+}
+
+// PostingSection: 表ごとのタイトルと項目
+type PostingSection struct {
+	Title string        `json:"title"`
+	Items []PostingItem `json:"items"`
+}
+
+// itemRefs: ラベルのセルと、そのラベルに続く値のセル
+type itemRefs struct {
+	label  CellRef
+	values []CellRef
+}
+
+// itemRefs はセクションのセルをラベルごとにまとめる。順序はラベルが最初に現れた順
+func (s Section) itemRefs() []itemRefs {
+	var items []itemRefs
+	index := map[CellRef]int{}
+	for _, c := range s.cells {
+		i, ok := index[c.label]
+		if !ok {
+			i = len(items)
+			index[c.label] = i
+			items = append(items, itemRefs{label: c.label})
+		}
+		if c.src != c.label {
+			items[i].values = append(items[i].values, c.src)
+		}
+	}
+	return items
+}
+
+// Items はセクションの項目名と値をラベルごとにまとめる
+func (s Section) Items(tableData [][]string) []PostingItem {
+	var items []PostingItem
+	for _, refs := range s.itemRefs() {
+		item := PostingItem{Label: refs.label.Value(tableData)}
+		for _, ref := range refs.values {
+			item.Values = append(item.Values, ref.Value(tableData))
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// PostingSections は求人票の各表をタイトルと項目の一覧に変換する
+func PostingSections(tableData [][]string) []PostingSection {
+	var sections []PostingSection
+	for _, s := range postingSections {
+		ps := PostingSection{Items: s.Items(tableData)}
+		if s.title != nil {
+			ps.Title = s.title.Value(tableData)
+		}
+		sections = append(sections, ps)
+	}
+	return sections
+}
+
+// postingLayout: 1シート分の描画結果（画像への描画で使う）
+type postingLayout struct {
+	texts  []Text        // 表の外のテキスト（タイトル・会社名）
+	tables []*Table      // 描画した表
+	images []placedImage // シートに貼り付けられた画像
+}
+
+// renderPosting は1シート分の求人票をpdfの現在のページから描画する
+func renderPosting(pdf *gofpdf.Fpdf, tableData [][]string, rich map[CellRef][]TextRun) postingLayout {
+	var layout postingLayout
+
+	// MARGIN CONFIG
+	marginSide := 30.0
+	marginTop := 13.0
+
+	// TITLE
+	pdf.SetFont("IPA", "", 20)
+	title := "求人票"
+	titleW := pdf.GetStringWidth(title)
+	_, titleH := pdf.GetFontSize()
+	pageW, _ := pdf.GetPageSize()
+	x := (pageW - titleW) / 2
+	y := marginTop
+	pdf.SetXY(x, y)
+	pdf.CellFormat(titleW, titleH, title, "", 0, "L", false, 0, "")
+	layout.texts = append(layout.texts, cellText(pdf, x, y, titleH, title, 20))
+
+	// COMPANY NAME
+	pdf.SetFontSize(7.0)
+	companyW := pdf.GetStringWidth(agencyName)
+	_, companyH := pdf.GetFontSize() // サイズ取得は不要だが、GetFontSize()を呼び出しておく
+	x = pageW - companyW - marginSide
+	y = marginTop
+	pdf.SetXY(x, y)
+	pdf.CellFormat(companyW, companyH, agencyName, "", 0, "L", false, 0, "")
+	layout.texts = append(layout.texts, cellText(pdf, x, y, companyH, agencyName, 7.0))
+
+	// TABLE ID
+	pdf.SetFillColor(153, 204, 255)
+	w := 5.0
+	offsetH := 2.0
+	pdf.SetXY(marginSide, marginTop+titleH)
+	pdf.SetFontSize(6.0)
+
+	ft := 6.0 // フォントサイズ
+	dh := 4.5 // デフォルトのセル高さ
+	currentH := marginTop + titleH + offsetH
+	tableID := NewTable(pdf, marginSide+w, currentH-idSection.rowH, pageW-marginSide, currentH, 9, idSection.rowNum, "IPA", ft, dh, "1")
+	idSection.Fill(tableID, tableData, rich)
+	tableID.Render(false)
+	layout.tables = append(layout.tables, tableID)
+
+	// TABLE A〜F
+	for _, s := range postingSections {
+		pdf.SetLineWidth(0.1)
+		table := NewTable(pdf, marginSide+w, currentH, pageW-marginSide, currentH+s.rowH, 9, s.rowNum, "IPA", ft, dh, "1")
+		s.Fill(table, tableData, rich)
+		table.Render(s.outline)
+		layout.tables = append(layout.tables, table)
+		currentH = table.Ys[len(table.Ys)-1] + offsetH
+	}
+
+	// Appendix
+	appendix := NewAppendix(pdf, marginSide, pageW-marginSide, currentH, "IPA", ft, dh, "0")
+	appendix.SetAppendix(appendixRef.Value(tableData), "L", false, -1.0, true)
+	appendix.Render(false)
+	layout.tables = append(layout.tables, appendix)
+
+	return layout
+}
+
+// cellText は左揃えの CellFormat で描かれる文字を、ベースライン基準の Text に変換する
+func cellText(pdf *gofpdf.Fpdf, x, y, h float64, text string, size float64) Text {
+	_, unitSize := pdf.GetFontSize()
+	return Text{
+		x:       x + pdf.GetCellMargin(),
+		y:       y + 0.5*h + 0.3*unitSize,
+		text:    text,
+		size:    size,
+		pageNum: pdf.PageNo(),
+	}
+}
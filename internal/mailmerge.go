@@ -0,0 +1,54 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// headerFields は見出しの行から、列ごとの項目を返す（見出しは項目のJSONキーまたは項目名）
+func headerFields(header []string) map[int]FieldDef {
+	fields := map[int]FieldDef{}
+	for i, h := range header {
+		if f, ok := fieldByHeader(h); ok {
+			fields[i] = f
+		}
+	}
+	return fields
+}
+
+// mergedRow: 一覧の1行から作った求人票
+type mergedRow struct {
+	row  int // 一覧の行番号（1始まり）
+	grid [][]string
+}
+
+// mergeRows は一覧の2行目以降を1行1件として、テンプレートの表（BlankGrid）の各項目に値を差し込む。空の行は読み飛ばす
+func mergeRows(rows [][]string, fields map[int]FieldDef) []mergedRow {
+	var merged []mergedRow
+	for i, row := range rows {
+		if i == 0 || strings.TrimSpace(strings.Join(row, "")) == "" {
+			continue
+		}
+		grid := BlankGrid()
+		for col, value := range row {
+			if f, ok := fields[col]; ok {
+				grid[f.Ref.Row][f.Ref.Col] = value
+			}
+		}
+		merged = append(merged, mergedRow{row: i + 1, grid: grid})
+	}
+	return merged
+}
+
+// mergeSheet はシートを求人の一覧として読み、1行ごとの求人票にする。
+// 1行目の見出しが項目と minHeaderMatches 個以上一致しない場合はエラーにする
+func mergeSheet(sheet string, rows [][]string) ([]mergedRow, error) {
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("シート %s: データがありません", sheet)
+	}
+	fields := headerFields(rows[0])
+	if len(fields) < minHeaderMatches {
+		return nil, fmt.Errorf("シート %s: 1行目に項目名の見出しがありません（差し込みには項目名またはJSONキーの見出しが%d列以上必要です）", sheet, minHeaderMatches)
+	}
+	return mergeRows(rows, fields), nil
+}
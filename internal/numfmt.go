@@ -0,0 +1,491 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// 組み込みの表示形式のうち、日本語版Excelでの表示に合わせて自前で整えるもの
+var builtInNumFmtCodes = map[int]string{
+	1:  "0",
+	2:  "0.00",
+	3:  "#,##0",
+	4:  "#,##0.00",
+	5:  `¥#,##0;¥-#,##0`,
+	6:  `¥#,##0;[Red]¥-#,##0`,
+	7:  `¥#,##0.00;¥-#,##0.00`,
+	8:  `¥#,##0.00;[Red]¥-#,##0.00`,
+	9:  "0%",
+	10: "0.00%",
+	14: "yyyy/m/d",
+	27: `[$-411]ge.m.d`,
+	28: `[$-411]ggge"年"m"月"d"日"`,
+	29: `[$-411]ggge"年"m"月"d"日"`,
+	30: "m/d/yy",
+	31: `yyyy"年"m"月"d"日"`,
+	34: `yyyy"年"m"月"`,
+	35: `m"月"d"日"`,
+	36: `[$-411]ge.m.d`,
+	37: "#,##0;-#,##0",
+	38: "#,##0;[Red]-#,##0",
+	39: "#,##0.00;-#,##0.00",
+	40: "#,##0.00;[Red]-#,##0.00",
+	50: `[$-411]ge.m.d`,
+	51: `[$-411]ggge"年"m"月"d"日"`,
+	52: `yyyy"年"m"月"`,
+	53: `m"月"d"日"`,
+	54: `[$-411]ggge"年"m"月"d"日"`,
+	55: `yyyy"年"m"月"`,
+	56: `m"月"d"日"`,
+	57: `[$-411]ge.m.d`,
+	58: `[$-411]ggge"年"m"月"d"日"`,
+}
+
+// 元号（新しい順）
+var japaneseEras = []struct {
+	name  string
+	abbr  string
+	start time.Time
+}{
+	{"令和", "R", time.Date(2019, 5, 1, 0, 0, 0, 0, time.UTC)},
+	{"平成", "H", time.Date(1989, 1, 8, 0, 0, 0, 0, time.UTC)},
+	{"昭和", "S", time.Date(1926, 12, 25, 0, 0, 0, 0, time.UTC)},
+	{"大正", "T", time.Date(1912, 7, 30, 0, 0, 0, 0, time.UTC)},
+	{"明治", "M", time.Date(1868, 1, 25, 0, 0, 0, 0, time.UTC)},
+}
+
+var weekdaysJa = []string{"日", "月", "火", "水", "木", "金", "土"}
+
+var (
+	numFmtBracketPattern = regexp.MustCompile(`\[[^\]]*\]`)
+	numFmtCurrencyTag    = regexp.MustCompile(`\[\$([^\-\]]*)(-[0-9A-Fa-f]+)?\]`)
+	plainNumberPattern   = regexp.MustCompile(`^[¥$]?\s*-?[0-9][0-9,]*(\.[0-9]+)?\s*(円)?$`)
+)
+
+// numFmtKind: 表示形式の種類
+type numFmtKind int
+
+const (
+	numFmtOther  numFmtKind = iota // 自前では整えない（excelize の表示を使う）
+	numFmtDate                     // 日付
+	numFmtNumber                   // 数値・通貨・パーセント
+)
+
+// stripQuoted は引用符の中とエスケープした文字を取り除いた表示形式を返す（種類の判定用）
+func stripQuoted(code string) string {
+	var b strings.Builder
+	quoted := false
+	for i := 0; i < len(code); i++ {
+		switch c := code[i]; {
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == '\\' || c == '_' || c == '*':
+			i++
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return numFmtBracketPattern.ReplaceAllString(b.String(), "")
+}
+
+// classifyNumFmt は表示形式が日付・数値のどちらかを判定する。時刻・分数・指数などは numFmtOther
+func classifyNumFmt(code string) numFmtKind {
+	section := strings.SplitN(code, ";", 2)[0]
+	s := strings.ToLower(stripQuoted(section))
+	switch {
+	case s == "" || s == "general" || strings.Contains(s, "@"):
+		return numFmtOther
+	case strings.ContainsAny(s, "hs") || strings.Contains(s, "am/pm"):
+		return numFmtOther
+	case strings.Contains(s, "e+") || strings.Contains(s, "e-") || strings.ContainsAny(s, "?"):
+		// 指数・分数
+		return numFmtOther
+	case strings.ContainsAny(s, "ymdge"):
+		return numFmtDate
+	case strings.Contains(s, "/"):
+		return numFmtOther
+	case strings.ContainsAny(s, "0#"):
+		return numFmtNumber
+	}
+	return numFmtOther
+}
+
+// excelSerialTime はExcelのシリアル値を日付にする
+func excelSerialTime(serial float64, date1904 bool) time.Time {
+	base := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	if date1904 {
+		base = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+	} else if serial < 61 {
+		// Excelは1900年2月29日が存在する扱いのため、それより前は1日ずれる
+		base = base.AddDate(0, 0, 1)
+	}
+	days := math.Floor(serial)
+	return base.AddDate(0, 0, int(days))
+}
+
+// japaneseEra は日付の元号と元号での年を返す
+func japaneseEra(t time.Time) (name, abbr string, year int) {
+	d := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	for _, e := range japaneseEras {
+		if !d.Before(e.start) {
+			return e.name, e.abbr, t.Year() - e.start.Year() + 1
+		}
+	}
+	return "", "", t.Year()
+}
+
+// formatDate は日付を表示形式で整える（y・m・d・g・e・aaa に対応）
+func formatDate(t time.Time, code string) string {
+	section := strings.SplitN(code, ";", 2)[0]
+	section = numFmtBracketPattern.ReplaceAllString(section, "")
+	eraName, eraAbbr, eraYear := japaneseEra(t)
+
+	var b strings.Builder
+	for i := 0; i < len(section); {
+		c := section[i]
+		switch c {
+		case '"':
+			end := strings.IndexByte(section[i+1:], '"')
+			if end < 0 {
+				b.WriteString(section[i+1:])
+				return b.String()
+			}
+			b.WriteString(section[i+1 : i+1+end])
+			i += end + 2
+			continue
+		case '\\':
+			if i+1 < len(section) {
+				// エスケープした文字（マルチバイト文字も1文字として扱う）
+				r := []rune(section[i+1:])[0]
+				b.WriteRune(r)
+				i += 1 + len(string(r))
+				continue
+			}
+		}
+		lower := c | 0x20
+		n := 1
+		for i+n < len(section) && section[i+n]|0x20 == lower {
+			n++
+		}
+		switch lower {
+		case 'y':
+			if n <= 2 {
+				b.WriteString(strconv.Itoa(t.Year() % 100))
+			} else {
+				b.WriteString(strconv.Itoa(t.Year()))
+			}
+		case 'm':
+			if n == 2 {
+				b.WriteString(t.Format("01"))
+			} else {
+				b.WriteString(strconv.Itoa(int(t.Month())))
+			}
+		case 'd':
+			switch {
+			case n == 1:
+				b.WriteString(strconv.Itoa(t.Day()))
+			case n == 2:
+				b.WriteString(t.Format("02"))
+			default:
+				b.WriteString(weekdaysJa[t.Weekday()])
+			}
+		case 'g':
+			switch {
+			case n >= 3:
+				b.WriteString(eraName)
+			case n == 2:
+				b.WriteString(string([]rune(eraName)[:1]))
+			default:
+				b.WriteString(eraAbbr)
+			}
+		case 'e':
+			if n >= 2 {
+				b.WriteString(strconv.Itoa(eraYear + 100)[1:])
+			} else {
+				b.WriteString(strconv.Itoa(eraYear))
+			}
+		case 'a':
+			if n >= 4 {
+				b.WriteString(weekdaysJa[t.Weekday()] + "曜日")
+			} else if n == 3 {
+				b.WriteString(weekdaysJa[t.Weekday()])
+			} else {
+				b.WriteString(section[i : i+n])
+			}
+		default:
+			b.WriteString(section[i : i+n])
+		}
+		i += n
+	}
+	return b.String()
+}
+
+// formatNumber は数値を表示形式で整える（桁区切り・小数点以下の桁数・パーセント・通貨記号などの文字に対応）。
+// 「000-0000」のように数字の指定が複数に分かれる形式には対応せず ok = false を返す
+func formatNumber(v float64, code string) (string, bool) {
+	sections := strings.Split(code, ";")
+	section := sections[0]
+	negative := v < 0
+	if negative && len(sections) > 1 && strings.TrimSpace(sections[1]) != "" {
+		section = sections[1]
+		v = -v
+		negative = false
+	}
+	// [$¥-411] のような通貨記号の指定は記号だけにする
+	section = numFmtCurrencyTag.ReplaceAllString(section, "$1")
+	section = numFmtBracketPattern.ReplaceAllString(section, "")
+
+	var prefix, suffix, pattern strings.Builder
+	inPattern, afterPattern := false, false
+	for i := 0; i < len(section); i++ {
+		c := section[i]
+		isPattern := strings.IndexByte("0#?.,", c) >= 0
+		if inPattern && !isPattern {
+			afterPattern = true
+		}
+		out := &prefix
+		if afterPattern {
+			out = &suffix
+		}
+		switch {
+		case c == '"':
+			end := strings.IndexByte(section[i+1:], '"')
+			if end < 0 {
+				end = len(section) - i - 1
+			}
+			out.WriteString(section[i+1 : i+1+end])
+			i += end + 1
+		case c == '\\' && i+1 < len(section):
+			r := []rune(section[i+1:])[0]
+			out.WriteRune(r)
+			i += len(string(r))
+		case c == '_' || c == '*':
+			// 幅合わせ・繰り返しの指定は空白1文字にする
+			if i+1 < len(section) {
+				i += len(string([]rune(section[i+1:])[0]))
+			}
+			if c == '_' {
+				out.WriteByte(' ')
+			}
+		case isPattern && !afterPattern:
+			inPattern = true
+			pattern.WriteByte(c)
+		default:
+			out.WriteByte(c)
+		}
+	}
+
+	if strings.ContainsAny(suffix.String(), "0#?") {
+		return "", false
+	}
+	p := pattern.String()
+	if strings.Contains(prefix.String()+suffix.String(), "%") {
+		v *= 100
+	}
+	// 末尾の「,」は1000で割る指定
+	for strings.HasSuffix(p, ",") {
+		p = strings.TrimSuffix(p, ",")
+		v /= 1000
+	}
+	decimals := 0
+	if i := strings.IndexByte(p, '.'); i >= 0 {
+		decimals = strings.Count(p[i:], "0") + strings.Count(p[i:], "#") + strings.Count(p[i:], "?")
+	}
+	// Excelと同じく四捨五入する（FormatFloat は偶数丸め）
+	scale := math.Pow(10, float64(decimals))
+	s := strconv.FormatFloat(math.Round(math.Abs(v)*scale)/scale, 'f', decimals, 64)
+	if i := strings.IndexByte(p, '.'); i >= 0 && !strings.Contains(p[i:], "0") {
+		// 小数点以下が「#」だけなら末尾の0を省く
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	if strings.Contains(p, ",") {
+		s = groupThousands(s)
+	}
+	if negative && s != strings.Repeat("0", len(s)) {
+		s = "-" + s
+	}
+	return strings.TrimSpace(prefix.String() + s + suffix.String()), true
+}
+
+// groupThousands は整数部を3桁ごとに「,」で区切る
+func groupThousands(s string) string {
+	intPart, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, frac = s[:i], s[i:]
+	}
+	var b strings.Builder
+	for i, c := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(c)
+	}
+	return b.String() + frac
+}
+
+// formatCellNumber はセルの数値（シリアル値）を表示形式で整える。自前で整えない形式なら ok = false
+func formatCellNumber(raw, code string, date1904 bool) (string, bool) {
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return "", false
+	}
+	switch classifyNumFmt(code) {
+	case numFmtDate:
+		return formatDate(excelSerialTime(v, date1904), code), true
+	case numFmtNumber:
+		return formatNumber(v, code)
+	}
+	return "", false
+}
+
+// formatValue は表示された値を表示形式 code で整え直す（項目マッピングでの表示形式の指定）。
+// 数値・日付として読めない値はそのまま返す
+func formatValue(value, code string) string {
+	s := strings.TrimSpace(toHalfWidth(value))
+	if s == "" {
+		return value
+	}
+	switch classifyNumFmt(code) {
+	case numFmtDate:
+		if t, ok := parsePostingDate(s); ok {
+			return formatDate(t, code)
+		}
+		if v, err := strconv.ParseFloat(s, 64); err == nil && v > 0 {
+			return formatDate(excelSerialTime(v, false), code)
+		}
+	case numFmtNumber:
+		percent := strings.HasSuffix(s, "%")
+		if percent {
+			s = strings.TrimSuffix(s, "%")
+		}
+		if !plainNumberPattern.MatchString(s) {
+			return value
+		}
+		s = strings.Trim(s, "¥$円 ")
+		v, err := strconv.ParseFloat(strings.ReplaceAll(s, ",", ""), 64)
+		if err != nil {
+			return value
+		}
+		if percent {
+			v /= 100
+		}
+		if formatted, ok := formatNumber(v, code); ok {
+			return formatted
+		}
+	}
+	return value
+}
+
+// cellFormatter: xlsxのセルの値を表示形式に従って整える
+type cellFormatter struct {
+	fx       *excelize.File
+	sheet    string
+	date1904 bool
+	codes    map[int]string // スタイル番号ごとの表示形式
+}
+
+func newCellFormatter(fx *excelize.File, sheet string) *cellFormatter {
+	c := &cellFormatter{fx: fx, sheet: sheet, codes: map[int]string{}}
+	if props, err := fx.GetWorkbookProps(); err == nil && props.Date1904 != nil {
+		c.date1904 = *props.Date1904
+	}
+	return c
+}
+
+// numFmtCode はスタイルの表示形式を返す
+func (c *cellFormatter) numFmtCode(styleID int) string {
+	if code, ok := c.codes[styleID]; ok {
+		return code
+	}
+	code := ""
+	if style, err := c.fx.GetStyle(styleID); err == nil {
+		if style.CustomNumFmt != nil {
+			code = *style.CustomNumFmt
+		} else {
+			code = builtInNumFmtCodes[style.NumFmt]
+		}
+	}
+	c.codes[styleID] = code
+	return code
+}
+
+// value は数値のセルを表示形式で整える。整えられない場合は excelize が表示した値 shown を返す
+func (c *cellFormatter) value(row, col int, shown string) string {
+	if shown == "" {
+		return shown
+	}
+	cell, err := excelize.CoordinatesToCellName(col+1, row+1)
+	if err != nil {
+		return shown
+	}
+	if t, err := c.fx.GetCellType(c.sheet, cell); err != nil ||
+		(t != excelize.CellTypeUnset && t != excelize.CellTypeNumber && t != excelize.CellTypeDate) {
+		return shown
+	}
+	raw, err := c.fx.GetCellValue(c.sheet, cell, excelize.Options{RawCellValue: true})
+	if err != nil {
+		return shown
+	}
+	if formatted, ok := c.format(cell, raw); ok {
+		return formatted
+	}
+	return shown
+}
+
+// format はセル cell の表示形式で数値 raw を整える
+func (c *cellFormatter) format(cell, raw string) (string, bool) {
+	styleID, err := c.fx.GetCellStyle(c.sheet, cell)
+	if err != nil {
+		return "", false
+	}
+	code := c.numFmtCode(styleID)
+	if code == "" {
+		return "", false
+	}
+	return formatCellNumber(raw, code, c.date1904)
+}
+
+// formula はキャッシュされた値のない数式を計算する。
+// 数式でないセルは空文字を、計算できない数式は注意事項を返す
+func (c *cellFormatter) formula(row, col int) (value string, warning string) {
+	cell, err := excelize.CoordinatesToCellName(col+1, row+1)
+	if err != nil {
+		return "", ""
+	}
+	f, err := c.fx.GetCellFormula(c.sheet, cell)
+	if err != nil || f == "" {
+		return "", ""
+	}
+	raw, err := c.fx.CalcCellValue(c.sheet, cell, excelize.Options{RawCellValue: true})
+	if err != nil {
+		return "", fmt.Sprintf("%s %s: 数式 =%s を計算できませんでした（%v）", c.sheet, cell, f, err)
+	}
+	if formatted, ok := c.format(cell, raw); ok {
+		return formatted, ""
+	}
+	return raw, ""
+}
+
+// applyFieldFormats は項目ごとに指定された表示形式で値を整え直す（キーは項目のJSONキーまたは項目名）
+func applyFieldFormats(tableData [][]string, formats map[string]string) {
+	if len(formats) == 0 {
+		return
+	}
+	for _, f := range postingFields {
+		code, ok := formats[f.Key]
+		if !ok {
+			code = formats[f.Label]
+		}
+		if code == "" || f.Ref.Row >= len(tableData) || f.Ref.Col >= len(tableData[f.Ref.Row]) {
+			continue
+		}
+		tableData[f.Ref.Row][f.Ref.Col] = formatValue(tableData[f.Ref.Row][f.Ref.Col], code)
+	}
+}
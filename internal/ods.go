@@ -0,0 +1,238 @@
+package internal
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const odsMimeType = "application/vnd.oasis.opendocument.spreadsheet"
+
+// OpenDocument の名前空間
+const (
+	odsTableNS = "urn:oasis:names:tc:opendocument:xmlns:table:1.0"
+	odsTextNS  = "urn:oasis:names:tc:opendocument:xmlns:text:1.0"
+	odsStyleNS = "urn:oasis:names:tc:opendocument:xmlns:style:1.0"
+)
+
+// readODS はODSファイル（content.xml）の各シートをテンプレートの読み取り範囲の表にする。
+// 結合セルは excelize と同じく左上のセルにだけ値を入れる
+func readODS(data []byte) (*gridWorkbook, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("ODSファイルとして開けません: %w", err)
+	}
+	files := map[string]*zip.File{}
+	for _, zf := range zr.File {
+		files[zf.Name] = zf
+	}
+
+	if mimetype, err := readZipFile(files["mimetype"]); err != nil || strings.TrimSpace(string(mimetype)) != odsMimeType {
+		return nil, fmt.Errorf("ODSファイルではありません")
+	}
+	if manifest, err := readZipFile(files["META-INF/manifest.xml"]); err == nil && bytes.Contains(manifest, []byte("encryption-data")) {
+		return nil, fmt.Errorf("パスワードで保護されたODSファイルには対応していません")
+	}
+	content, err := readZipFile(files["content.xml"])
+	if err != nil {
+		return nil, fmt.Errorf("content.xml の読み込みに失敗: %w", err)
+	}
+
+	w := newGridWorkbook()
+	d := xml.NewDecoder(bytes.NewReader(content))
+	// 非表示のシートは、表のスタイルの table:display が false になっている（スタイルは表より前にある）
+	hiddenStyles := map[string]bool{}
+	style := ""
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("content.xml の解析に失敗: %w", err)
+		}
+		se, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch {
+		case se.Name.Space == odsStyleNS && se.Name.Local == "style":
+			style = odsAttr(se, odsStyleNS, "name")
+		case se.Name.Space == odsStyleNS && se.Name.Local == "table-properties":
+			if odsAttr(se, odsTableNS, "display") == "false" {
+				hiddenStyles[style] = true
+			}
+		case se.Name.Space == odsTableNS && se.Name.Local == "table":
+			name := odsAttr(se, odsTableNS, "name")
+			grid, truncated, err := readODSTable(d)
+			if err != nil {
+				return nil, fmt.Errorf("シート %s の読み込みに失敗: %w", name, err)
+			}
+			w.add(name, grid)
+			if truncated {
+				w.addTruncated(name)
+			}
+			w.hidden[name] = hiddenStyles[odsAttr(se, odsTableNS, "style-name")]
+		}
+	}
+	return w, nil
+}
+
+func readZipFile(zf *zip.File) ([]byte, error) {
+	if zf == nil {
+		return nil, fmt.Errorf("ファイルがありません")
+	}
+	rc, err := zf.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+func odsAttr(se xml.StartElement, space, local string) string {
+	for _, a := range se.Attr {
+		if a.Name.Space == space && a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// odsRepeat は number-rows-repeated / number-columns-repeated の値を返す
+func odsRepeat(se xml.StartElement, local string) int {
+	n, err := strconv.Atoi(odsAttr(se, odsTableNS, local))
+	if err != nil || n < 1 {
+		return 1
+	}
+	return n
+}
+
+// readODSTable は <table:table> の中を読み、読み取り範囲の上限（maxReadRows・maxReadCols）までの行と列を取り出す。
+// 空行・空セルの繰り返し（シート末尾まで続くことがある）は上限までの分だけ展開する。
+// 上限を超える値があった場合は truncated が true
+func readODSTable(d *xml.Decoder) (grid [][]string, truncated bool, err error) {
+	var rows [][]string
+	var row []string
+	rowRepeat := 1
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, false, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Space != odsTableNS {
+				continue
+			}
+			switch t.Name.Local {
+			case "table-row":
+				row = []string{}
+				rowRepeat = odsRepeat(t, "number-rows-repeated")
+			case "table-cell", "covered-table-cell":
+				text, err := readODSCell(d, t.Name)
+				if err != nil {
+					return nil, false, err
+				}
+				if t.Name.Local == "covered-table-cell" {
+					text = ""
+				}
+				for i := 0; i < odsRepeat(t, "number-columns-repeated"); i++ {
+					if len(row) >= maxReadCols {
+						truncated = truncated || text != ""
+						break
+					}
+					row = append(row, text)
+				}
+			case "table":
+				// 入れ子の表（サブテーブル）は読み飛ばす
+				if err := d.Skip(); err != nil {
+					return nil, false, err
+				}
+			}
+		case xml.EndElement:
+			if t.Name.Space != odsTableNS {
+				continue
+			}
+			switch t.Name.Local {
+			case "table-row":
+				for i := 0; i < rowRepeat; i++ {
+					if len(rows) >= maxReadRows {
+						truncated = truncated || strings.Join(row, "") != ""
+						break
+					}
+					rows = append(rows, row)
+				}
+			case "table":
+				grid, cut := toGrid(trimEmptyRows(rows))
+				return grid, truncated || cut, nil
+			}
+		}
+	}
+}
+
+// readODSCell はセル内の段落を改行でつないだ文字列を返す
+func readODSCell(d *xml.Decoder, name xml.Name) (string, error) {
+	var b strings.Builder
+	paragraphs := 0
+	depth := 0 // 段落（text:p / text:h）の中か
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return "", err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Space != odsTextNS {
+				if t.Name.Local == "annotation" {
+					// コメントは値に含めない
+					if err := d.Skip(); err != nil {
+						return "", err
+					}
+				}
+				continue
+			}
+			switch t.Name.Local {
+			case "p", "h":
+				if paragraphs > 0 {
+					b.WriteString("\n")
+				}
+				paragraphs++
+				depth++
+			case "line-break":
+				b.WriteString("\n")
+			case "tab":
+				b.WriteString("\t")
+			case "s":
+				n, err := strconv.Atoi(odsAttr(t, odsTextNS, "c"))
+				if err != nil || n < 1 {
+					n = 1
+				}
+				b.WriteString(strings.Repeat(" ", n))
+			}
+		case xml.CharData:
+			if depth > 0 {
+				b.Write(t)
+			}
+		case xml.EndElement:
+			if t.Name == name {
+				return b.String(), nil
+			}
+			if t.Name.Space == odsTextNS && (t.Name.Local == "p" || t.Name.Local == "h") {
+				depth--
+			}
+		}
+	}
+}
+
+// trimEmptyRows は末尾の空行を取り除く
+func trimEmptyRows(rows [][]string) [][]string {
+	for len(rows) > 0 && strings.Join(rows[len(rows)-1], "") == "" {
+		rows = rows[:len(rows)-1]
+	}
+	return rows
+}
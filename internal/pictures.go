@@ -0,0 +1,219 @@
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/xuri/excelize/v2"
+	xdraw "golang.org/x/image/draw"
+)
+
+// 元の位置に置けない画像の配置先（ConvertOptions.ImageSlot）
+const (
+	imageSlotFooter = "footer" // 求人票の末尾（既定）
+	imageSlotHeader = "header" // 1ページ目の左上（タイトルの左）
+	imageSlotNone   = "none"   // 出力しない
+)
+
+// 画像の配置先の大きさ（mm）。余白は renderPosting と同じ
+const (
+	pictureMarginSide   = 30.0
+	pictureMarginTop    = 13.0
+	pictureMarginBottom = 20.0
+	pictureHeaderW      = 40.0
+	pictureHeaderH      = 10.0
+	pictureFooterH      = 40.0
+	pictureGap          = 3.0
+)
+
+// sheetPicture: シートに貼り付けられた画像
+type sheetPicture struct {
+	cell      CellRef     // 画像の左上のセル
+	data      []byte      // PDFに埋め込む画像（JPEGはそのまま、PNGは8bitに変換したもの）
+	imageType string      // "JPG" または "PNG"
+	img       image.Image // サムネイル用
+	width     float64     // 元の大きさ（mm、96dpi）
+	height    float64
+}
+
+// placedImage: PDFに配置した画像
+type placedImage struct {
+	x, y, w, h float64
+	pageNum    int
+	pic        *sheetPicture
+}
+
+// readPictures は読み取り範囲（rows 行 × cols 列）のセルを左上とする画像を読み取る。
+// JPEG・PNG以外の画像は注意事項にして読み飛ばす
+func readPictures(fx *excelize.File, sheet string, rows, cols int) ([]sheetPicture, []string, error) {
+	cells, err := fx.GetPictureCells(sheet)
+	if err != nil {
+		return nil, nil, err
+	}
+	var pictures []sheetPicture
+	var warnings []string
+	for _, cell := range cells {
+		c, r, err := excelize.CellNameToCoordinates(cell)
+		if err != nil || r > rows || c > cols {
+			continue
+		}
+		pics, err := fx.GetPictures(sheet, cell)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, p := range pics {
+			pic, err := decodePicture(p.Extension, p.File)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("%s %s: 画像を出力できません（%v）", sheet, cell, err))
+				continue
+			}
+			pic.cell = at(r-1, c-1)
+			pictures = append(pictures, pic)
+		}
+	}
+	return pictures, warnings, nil
+}
+
+// decodePicture は画像を読み込み、PDFに埋め込める形にする
+func decodePicture(ext string, data []byte) (sheetPicture, error) {
+	var pic sheetPicture
+	switch strings.ToLower(ext) {
+	case ".jpg", ".jpeg":
+		img, err := jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			return pic, fmt.Errorf("JPEGの読み込みに失敗: %w", err)
+		}
+		pic.data, pic.imageType, pic.img = data, "JPG", img
+	case ".png":
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			return pic, fmt.Errorf("PNGの読み込みに失敗: %w", err)
+		}
+		// gofpdf はインターレースや16bitのPNGを扱えないため、8bitで書き直す
+		nrgba := image.NewNRGBA(img.Bounds())
+		draw.Draw(nrgba, nrgba.Bounds(), img, img.Bounds().Min, draw.Src)
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, nrgba); err != nil {
+			return pic, fmt.Errorf("PNGの変換に失敗: %w", err)
+		}
+		pic.data, pic.imageType, pic.img = buf.Bytes(), "PNG", nrgba
+	default:
+		return pic, fmt.Errorf("%s 形式には対応していません。JPEGかPNGにしてください", strings.TrimPrefix(strings.ToLower(ext), "."))
+	}
+	b := pic.img.Bounds()
+	pic.width = float64(b.Dx()) * 25.4 / 96
+	pic.height = float64(b.Dy()) * 25.4 / 96
+	return pic, nil
+}
+
+// fitSize は縦横比を保ったまま maxW × maxH に収まる大きさを返す（拡大はしない）
+func fitSize(w, h, maxW, maxH float64) (float64, float64) {
+	if w <= 0 || h <= 0 {
+		return 0, 0
+	}
+	scale := min(1, maxW/w, maxH/h)
+	return w * scale, h * scale
+}
+
+// drawPicture は画像を pdf の現在のページに描く
+func drawPicture(pdf *gofpdf.Fpdf, p placedImage) {
+	h := fnv.New64a()
+	h.Write(p.pic.data)
+	name := fmt.Sprintf("picture_%x", h.Sum64())
+	opts := gofpdf.ImageOptions{ImageType: p.pic.imageType}
+	pdf.RegisterImageOptionsReader(name, opts, bytes.NewReader(p.pic.data))
+	pdf.ImageOptions(name, p.x, p.y, p.w, p.h, false, opts, 0, "")
+}
+
+// placeTemplatePictures はテンプレートのレイアウトに画像を配置する。
+// 値のセルに貼り付けられた画像はそのセルの中に、それ以外は slot の位置に置く
+func placeTemplatePictures(pdf *gofpdf.Fpdf, layout *postingLayout, pictures []sheetPicture, slot string) {
+	if len(pictures) == 0 || len(layout.tables) == 0 {
+		return
+	}
+	sections := append([]Section{idSection}, postingSections...)
+	var rest []*sheetPicture
+	for i := range pictures {
+		pic := &pictures[i]
+		placed := false
+		for si, s := range sections {
+			if si >= len(layout.tables) {
+				break
+			}
+			t := layout.tables[si]
+			for _, c := range s.cells {
+				if c.src != pic.cell || c.row_f >= len(t.Rows) || t.Rows[c.row_i].pageNum != t.Rows[c.row_f].pageNum {
+					continue
+				}
+				x, y := t.Xs[c.col_i], t.Rows[c.row_i].y
+				cw, ch := t.Xs[c.col_f]-x, t.Rows[c.row_f].y-y
+				w, h := fitSize(pic.width, pic.height, cw-1, ch-1)
+				layout.images = append(layout.images, placedImage{x: x + (cw-w)/2, y: y + (ch-h)/2, w: w, h: h, pageNum: t.Rows[c.row_i].pageNum, pic: pic})
+				placed = true
+				break
+			}
+			if placed {
+				break
+			}
+		}
+		if !placed {
+			rest = append(rest, pic)
+		}
+	}
+
+	pageW, pageH := pdf.GetPageSize()
+	switch slot {
+	case imageSlotNone:
+	case imageSlotHeader:
+		x := pictureMarginSide
+		for _, pic := range rest {
+			w, h := fitSize(pic.width, pic.height, pictureHeaderW, pictureHeaderH)
+			layout.images = append(layout.images, placedImage{x: x, y: pictureMarginTop, w: w, h: h, pageNum: layout.tables[0].initialpageNum, pic: pic})
+			x += w + pictureGap
+		}
+	default:
+		// 最後の表（付録）の下から左詰めで並べ、入らなければ改ページする
+		last := layout.tables[len(layout.tables)-1]
+		y := last.GetBottomLine(pdf.PageNo())
+		if y == 0 {
+			y = last.Ys[len(last.Ys)-1]
+		}
+		x := pictureMarginSide
+		y += pictureGap
+		rowH := 0.0
+		for _, pic := range rest {
+			w, h := fitSize(pic.width, pic.height, pageW-2*pictureMarginSide, pictureFooterH)
+			if x > pictureMarginSide && x+w > pageW-pictureMarginSide {
+				x, y, rowH = pictureMarginSide, y+rowH+pictureGap, 0
+			}
+			if y+h > pageH-pictureMarginBottom {
+				pdf.AddPage()
+				x, y, rowH = pictureMarginSide, pictureMarginTop, 0
+			}
+			layout.images = append(layout.images, placedImage{x: x, y: y, w: w, h: h, pageNum: pdf.PageNo(), pic: pic})
+			x += w + pictureGap
+			rowH = max(rowH, h)
+		}
+	}
+
+	// 前のページに置く画像があるため、描いたあとで最後のページに戻す
+	current := pdf.PageNo()
+	for _, p := range layout.images {
+		pdf.SetPage(p.pageNum)
+		drawPicture(pdf, p)
+	}
+	pdf.SetPage(current)
+}
+
+// rasterize は画像をサムネイルに描く
+func (p placedImage) rasterize(c *pageCanvas) {
+	r := image.Rect(int(p.x*c.scale), int(p.y*c.scale), int((p.x+p.w)*c.scale), int((p.y+p.h)*c.scale))
+	xdraw.CatmullRom.Scale(c.img, r, p.pic.img, p.pic.img.Bounds(), xdraw.Over, nil)
+}
@@ -0,0 +1,220 @@
+package internal
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+)
+
+// FieldDef: 求人票の項目と、シート上の値のセル位置の対応
+type FieldDef struct {
+	Key   string  // JobPosting のJSONキー
+	Label string  // 項目名（テンプレートのラベル）
+	Ref   CellRef // 値のセル
+}
+
+// postingFields: 現行テンプレートの項目マッピング（表示順）
+var postingFields = []FieldDef{
+	{"id", "求人番号", at(2, 27)},
+
+	// 募集要項
+	{"jobTitleKana", "職種（ふりがな）", at(3, 2)},
+	{"jobTitle", "職種", at(4, 2)},
+	{"employmentType", "雇用形態", at(3, 24)},
+	{"contractPeriod", "契約期間", at(5, 2)},
+	{"trialPeriod", "試用期間", at(5, 13)},
+	{"openings", "募集人数", at(5, 24)},
+	{"industry", "業種", at(6, 2)},
+	{"jobCategory", "職種分類", at(6, 13)},
+	{"department", "配属部署", at(7, 2)},
+	{"description", "仕事内容", at(8, 2)},
+	{"requirements", "応募資格", at(9, 2)},
+	{"preferred", "歓迎要件", at(10, 2)},
+
+	// 勤務条件
+	{"location", "勤務地", at(12, 2)},
+	{"access", "アクセス", at(13, 2)},
+	{"salaryType", "給与", at(14, 2)},
+	{"salaryMin", "給与下限", at(14, 13)},
+	{"salaryMax", "給与上限", at(14, 24)},
+	{"salaryNote", "給与備考", at(15, 13)},
+	{"hours", "勤務時間", at(16, 2)},
+	{"overtime", "残業", at(17, 2)},
+	{"holidays", "休日休暇", at(18, 2)},
+	{"insurance", "社会保険", at(19, 2)},
+	{"smokingPolicy", "受動喫煙対策", at(19, 13)},
+	{"raise", "昇給", at(20, 2)},
+	{"bonus", "賞与", at(20, 13)},
+	{"commute", "交通費", at(20, 24)},
+	{"benefits", "福利厚生", at(21, 2)},
+
+	// 選考情報
+	{"deadline", "応募締切", at(23, 2)},
+	{"startDate", "入社日", at(23, 13)},
+	{"selectionProcess", "選考プロセス", at(24, 2)},
+
+	// 企業情報
+	{"companyName", "会社名", at(26, 2)},
+	{"established", "設立", at(26, 13)},
+	{"capital", "資本金", at(26, 24)},
+	{"employees", "従業員数", at(27, 2)},
+	{"revenue", "売上高", at(27, 13)},
+	{"representative", "代表者", at(27, 24)},
+	{"business", "事業内容", at(28, 2)},
+	{"companyAddress", "本社所在地", at(29, 2)},
+	{"companyFeatures", "会社の特徴", at(30, 2)},
+	{"website", "ホームページ", at(31, 2)},
+	{"listing", "上場区分", at(31, 24)},
+
+	// その他
+	{"workEnvironment", "職場環境", at(33, 2)},
+	{"dressCode", "服装", at(33, 24)},
+	{"careerPath", "キャリアパス", at(34, 2)},
+	{"message", "メッセージ", at(35, 2)},
+
+	{"notes", "備考", at(37, 2)},
+	{"notice", "注記", at(41, 0)},
+}
+
+// JobPosting: 1シート分の求人票の内容
+type JobPosting struct {
+	ID string `json:"id"`
+
+	JobTitleKana   string `json:"jobTitleKana"`
+	JobTitle       string `json:"jobTitle"`
+	EmploymentType string `json:"employmentType"`
+	ContractPeriod string `json:"contractPeriod"`
+	TrialPeriod    string `json:"trialPeriod"`
+	Openings       string `json:"openings"`
+	Industry       string `json:"industry"`
+	JobCategory    string `json:"jobCategory"`
+	Department     string `json:"department"`
+	Description    string `json:"description"`
+	Requirements   string `json:"requirements"`
+	Preferred      string `json:"preferred"`
+
+	Location      string `json:"location"`
+	Access        string `json:"access"`
+	SalaryType    string `json:"salaryType"`
+	SalaryMin     string `json:"salaryMin"`
+	SalaryMax     string `json:"salaryMax"`
+	SalaryNote    string `json:"salaryNote"`
+	Hours         string `json:"hours"`
+	Overtime      string `json:"overtime"`
+	Holidays      string `json:"holidays"`
+	Insurance     string `json:"insurance"`
+	SmokingPolicy string `json:"smokingPolicy"`
+	Raise         string `json:"raise"`
+	Bonus         string `json:"bonus"`
+	Commute       string `json:"commute"`
+	Benefits      string `json:"benefits"`
+
+	Deadline         string `json:"deadline"`
+	StartDate        string `json:"startDate"`
+	SelectionProcess string `json:"selectionProcess"`
+
+	CompanyName     string `json:"companyName"`
+	Established     string `json:"established"`
+	Capital         string `json:"capital"`
+	Employees       string `json:"employees"`
+	Revenue         string `json:"revenue"`
+	Representative  string `json:"representative"`
+	Business        string `json:"business"`
+	CompanyAddress  string `json:"companyAddress"`
+	CompanyFeatures string `json:"companyFeatures"`
+	Website         string `json:"website"`
+	Listing         string `json:"listing"`
+
+	WorkEnvironment string `json:"workEnvironment"`
+	DressCode       string `json:"dressCode"`
+	CareerPath      string `json:"careerPath"`
+	Message         string `json:"message"`
+
+	Notes  string `json:"notes"`
+	Notice string `json:"notice"`
+}
+
+// JSONキーから JobPosting のフィールド番号への対応
+var jobPostingFieldIndex = func() map[string]int {
+	index := map[string]int{}
+	t := reflect.TypeOf(JobPosting{})
+	for i := 0; i < t.NumField(); i++ {
+		index[t.Field(i).Tag.Get("json")] = i
+	}
+	return index
+}()
+
+// Field はJSONキーに対応する値を返す
+func (p *JobPosting) Field(key string) string {
+	i, ok := jobPostingFieldIndex[key]
+	if !ok {
+		return ""
+	}
+	return reflect.ValueOf(p).Elem().Field(i).String()
+}
+
+// SetField はJSONキーに対応するフィールドに値を入れる
+func (p *JobPosting) SetField(key, value string) {
+	i, ok := jobPostingFieldIndex[key]
+	if !ok {
+		return
+	}
+	reflect.ValueOf(p).Elem().Field(i).SetString(value)
+}
+
+// NewJobPosting は項目マッピングに従ってtableDataから求人票の内容を取り出す
+func NewJobPosting(tableData [][]string) JobPosting {
+	var p JobPosting
+	for _, f := range postingFields {
+		p.SetField(f.Key, f.Ref.Value(tableData))
+	}
+	return p
+}
+
+// WritePostingJSON はシートごとの求人票をJSON配列として書き出す
+func WritePostingJSON(w io.Writer, postings [][][]string) error {
+	jobs := []JobPosting{}
+	for _, tableData := range postings {
+		jobs = append(jobs, NewJobPosting(tableData))
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(jobs)
+}
+
+// sectionTitles: 現行テンプレートの各表のタイトル
+var sectionTitles = map[CellRef]string{
+	at(3, 0):  "募集要項",
+	at(12, 0): "勤務条件",
+	at(23, 0): "選考情報",
+	at(26, 0): "企業情報",
+	at(33, 0): "その他",
+}
+
+// BlankGrid はテンプレートのタイトルとラベルだけを入れた空の表（48行×30列）を返す。
+// ラベルは項目マッピングから取り、複数の項目で共有するラベル（ふりがなと職種）は後の項目名を使う
+func BlankGrid() [][]string {
+	grid := make([][]string, gridRows)
+	for i := range grid {
+		grid[i] = make([]string, gridCols)
+	}
+	set := func(ref CellRef, text string) {
+		if ref.Row < gridRows && ref.Col < gridCols {
+			grid[ref.Row][ref.Col] = text
+		}
+	}
+	for ref, title := range sectionTitles {
+		set(ref, title)
+	}
+	for _, s := range append([]Section{idSection}, postingSections...) {
+		for _, refs := range s.itemRefs() {
+			for _, v := range refs.values {
+				if f, ok := fieldByRef[v]; ok {
+					set(refs.label, f.Label)
+				}
+			}
+		}
+	}
+	return grid
+}
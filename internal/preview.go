@@ -0,0 +1,119 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PostingPreview: 保存前に確認するための変換結果（ファイルには書き出さない）
+type PostingPreview struct {
+	Name     string             `json:"name"`
+	Pages    []string           `json:"pages"`    // ページごとのPNG画像（data URL）
+	PDF      string             `json:"pdf"`      // PDF（Base64。SavePreviewPDF で保存する）
+	FileName string             `json:"fileName"` // PDFを保存するときのファイル名
+	Sheets   [][]PostingSection `json:"sheets"`   // シートごとの項目と値
+	Warnings []string           `json:"warnings"` // 読み込み中の注意事項
+}
+
+// ファイルを変換し、ページ画像・PDF・読み取った値を返す（保存はしない）。
+// opts のうち、読み込みと描画の設定（パスワード・表示形式・シートの書式・画像の配置先・変換するシート・差し込み・入力ルール）を使う
+func (a *App) PreviewPosting(f FileData, opts ConvertOptions) (PostingPreview, error) {
+	preview := PostingPreview{Name: f.Name}
+
+	// フォントファイルの読み込み
+	fontFile, fontPath, err := a.loadFont()
+	if err != nil {
+		return preview, err
+	}
+	defer fontFile.Close()
+	defer os.Remove(fontPath)
+
+	ft, err := parseFont()
+	if err != nil {
+		return preview, err
+	}
+
+	// ZIPファイルは最初の表計算ファイルをプレビューする
+	if isArchive(f.Name) {
+		files, _, err := expandArchive(f)
+		if err != nil {
+			return preview, err
+		}
+		f = files[0]
+	}
+
+	loaded, err := a.loadPostings(f, loadOptions{
+		passwords: append([]string{f.Password}, opts.Passwords...),
+		formats:   opts.Formats,
+		layout:    opts.SheetLayout,
+		sheets:    opts.Sheets,
+		mailMerge: opts.MailMerge,
+	})
+	if err != nil {
+		return preview, err
+	}
+	postings := loaded.postings
+	preview.Warnings = loaded.warnings
+	rules, err := compileRules(opts.Rules)
+	if err != nil {
+		return preview, err
+	}
+	ruleWarnings, blocked := loaded.checkRules(rules)
+	preview.Warnings = append(preview.Warnings, ruleWarnings...)
+
+	pdf, layouts := buildPostingPDF(fontPath, loaded, opts.ImageSlot)
+	pageNum := pdf.PageNo()
+	// 入力エラーがあるシートは画像では確認できるようにし、保存するPDFには含めない
+	saved := loaded
+	if len(blocked) > 0 {
+		preview.Warnings = append(preview.Warnings, fmt.Sprintf("入力エラーがあるシート（%s）は保存するPDFに含めません", loaded.blockedSheets(blocked)))
+		saved = loaded.without(blocked)
+		pdf = nil
+		if len(saved.postings) > 0 {
+			pdf, _ = buildPostingPDF(fontPath, saved, opts.ImageSlot)
+		}
+	}
+	if pdf != nil {
+		var buf bytes.Buffer
+		if err := pdf.Output(&buf); err != nil {
+			return preview, fmt.Errorf("%s のPDF出力に失敗: %w", f.Name, err)
+		}
+		preview.PDF = base64.StdEncoding.EncodeToString(buf.Bytes())
+		preview.FileName = postingFileName(f.Name, saved.postings, ".pdf")
+	}
+
+	for _, img := range rasterizePages(ft, layouts, pageNum, previewDPI) {
+		var b bytes.Buffer
+		if err := WritePNG(&b, img); err != nil {
+			return preview, fmt.Errorf("%s のプレビュー画像の作成に失敗: %w", f.Name, err)
+		}
+		preview.Pages = append(preview.Pages, "data:image/png;base64,"+base64.StdEncoding.EncodeToString(b.Bytes()))
+	}
+
+	for _, tableData := range postings {
+		preview.Sheets = append(preview.Sheets, PostingSections(tableData))
+	}
+	return preview, nil
+}
+
+// プレビューで確認したPDFをそのままダウンロードフォルダに保存し、保存したパスを返す
+func (a *App) SavePreviewPDF(preview PostingPreview) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(preview.PDF)
+	if err != nil || len(data) == 0 {
+		return "", fmt.Errorf("%s: 保存するPDFがありません", preview.Name)
+	}
+	Dpath, err := GetDownloadsPath()
+	if err != nil {
+		return "", fmt.Errorf("ダウンロードパスの取得に失敗: %w", err)
+	}
+	path := uniquePath(filepath.Join(Dpath, joinFileName(strings.TrimSuffix(filepath.Base(preview.FileName), ".pdf"))+".pdf"))
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return "", fmt.Errorf("%s のPDF出力に失敗: %w", preview.Name, err)
+	}
+	fmt.Printf("PDFファイルを保存しました: %s\n", path)
+	return path, nil
+}
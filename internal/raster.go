@@ -0,0 +1,242 @@
+package internal
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// 画像描画の設定（PDFと同じA4・mm単位）
+const (
+	rasterPageW      = 210.0
+	rasterPageH      = 297.0
+	rasterCellMargin = 1.0 // gofpdf の CellFormat のセル内余白
+	thumbnailDPI     = 48.0
+	previewDPI       = 96.0
+)
+
+var rasterFillColor = color.RGBA{R: 153, G: 204, B: 255, A: 255}
+
+// pageCanvas: 1ページ分の画像。座標はPDFと同じmmで受け取り、ピクセルに変換して描く
+type pageCanvas struct {
+	img   *image.RGBA
+	scale float64 // 1mmあたりのピクセル数
+	dpi   float64
+	font  *opentype.Font
+	faces map[float64]font.Face
+}
+
+func newPageCanvas(f *opentype.Font, dpi float64) *pageCanvas {
+	scale := dpi / 25.4
+	img := image.NewRGBA(image.Rect(0, 0, int(math.Ceil(rasterPageW*scale)), int(math.Ceil(rasterPageH*scale))))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+	return &pageCanvas{
+		img:   img,
+		scale: scale,
+		dpi:   dpi,
+		font:  f,
+		faces: map[float64]font.Face{},
+	}
+}
+
+// face はフォントサイズ（pt）ごとのフェイスを返す
+func (c *pageCanvas) face(size float64) font.Face {
+	if face, ok := c.faces[size]; ok {
+		return face
+	}
+	face, err := opentype.NewFace(c.font, &opentype.FaceOptions{Size: size, DPI: c.dpi, Hinting: font.HintingNone})
+	if err != nil {
+		fmt.Print("[Raster] Failed to create font face: ", err, "\n")
+		return nil
+	}
+	c.faces[size] = face
+	return face
+}
+
+// fillPx はピクセル座標の矩形を塗りつぶす
+func (c *pageCanvas) fillPx(x0, y0, x1, y1 float64, col color.Color) {
+	r := image.Rect(int(math.Round(x0)), int(math.Round(y0)), int(math.Round(x1)), int(math.Round(y1)))
+	draw.Draw(c.img, r, &image.Uniform{C: col}, image.Point{}, draw.Src)
+}
+
+func (c *pageCanvas) fillRect(x, y, w, h float64, col color.Color) {
+	c.fillPx(x*c.scale, y*c.scale, (x+w)*c.scale, (y+h)*c.scale, col)
+}
+
+// strokeRect は矩形の枠線を描く。線の太さは最低1ピクセル
+func (c *pageCanvas) strokeRect(x, y, w, h, lineWidth float64) {
+	t := math.Max(1, math.Round(lineWidth*c.scale))
+	x0 := x*c.scale - t/2
+	y0 := y*c.scale - t/2
+	x1 := (x+w)*c.scale - t/2
+	y1 := (y+h)*c.scale - t/2
+	c.fillPx(x0, y0, x1+t, y0+t, color.Black) // 上
+	c.fillPx(x0, y1, x1+t, y1+t, color.Black) // 下
+	c.fillPx(x0, y0, x0+t, y1+t, color.Black) // 左
+	c.fillPx(x1, y0, x1+t, y1+t, color.Black) // 右
+}
+
+// strokeSides は sides（"L"・"T"・"R"・"B" の組み合わせ）の辺だけ枠線を描く
+func (c *pageCanvas) strokeSides(x, y, w, h, lineWidth float64, sides string) {
+	t := math.Max(1, math.Round(lineWidth*c.scale))
+	x0 := x*c.scale - t/2
+	y0 := y*c.scale - t/2
+	x1 := (x+w)*c.scale - t/2
+	y1 := (y+h)*c.scale - t/2
+	if strings.Contains(sides, "T") {
+		c.fillPx(x0, y0, x1+t, y0+t, color.Black)
+	}
+	if strings.Contains(sides, "B") {
+		c.fillPx(x0, y1, x1+t, y1+t, color.Black)
+	}
+	if strings.Contains(sides, "L") {
+		c.fillPx(x0, y0, x0+t, y1+t, color.Black)
+	}
+	if strings.Contains(sides, "R") {
+		c.fillPx(x1, y0, x1+t, y1+t, color.Black)
+	}
+}
+
+// rasterFill は塗りつぶしの色（"RRGGBB"。空の場合は既定の色）を返す
+func rasterFill(hex string) color.Color {
+	if r, g, b, ok := parseHexColor(hex); ok {
+		return color.RGBA{R: uint8(r), G: uint8(g), B: uint8(b), A: 255}
+	}
+	return rasterFillColor
+}
+
+// stringWidth は文字列の幅をmmで返す
+func (c *pageCanvas) stringWidth(text string, size float64) float64 {
+	face := c.face(size)
+	if face == nil {
+		return 0
+	}
+	return float64(font.MeasureString(face, text)) / 64 / c.scale
+}
+
+// text はベースライン(x, y)から文字列を描く
+func (c *pageCanvas) text(x, y float64, text string, size float64) {
+	c.textColor(x, y, text, size, color.Black)
+}
+
+// textColor は文字の色を指定して text と同じように描く
+func (c *pageCanvas) textColor(x, y float64, text string, size float64, col color.Color) {
+	face := c.face(size)
+	if face == nil {
+		return
+	}
+	d := font.Drawer{
+		Dst:  c.img,
+		Src:  image.NewUniform(col),
+		Face: face,
+		Dot:  fixed.Point26_6{X: fixed.Int26_6(x * c.scale * 64), Y: fixed.Int26_6(y * c.scale * 64)},
+	}
+	d.DrawString(text)
+}
+
+// cellFormat は gofpdf の CellFormat と同じ配置でセルを描く
+func (c *pageCanvas) cellFormat(cell CellInfo) {
+	if cell.fill {
+		c.fillRect(cell.x, cell.y, cell.w, cell.h, rasterFill(cell.fillColor))
+	}
+	if cell.border == "1" {
+		c.strokeRect(cell.x, cell.y, cell.w, cell.h, cell.LineWidth)
+	} else if cell.border != "0" && cell.border != "" {
+		c.strokeSides(cell.x, cell.y, cell.w, cell.h, cell.LineWidth, cell.border)
+	}
+	if cell.text == "" {
+		return
+	}
+	if len(cell.runs) > 0 {
+		c.drawRuns(cell)
+		return
+	}
+	dx := rasterCellMargin
+	switch cell.align {
+	case "R":
+		dx = cell.w - rasterCellMargin - c.stringWidth(cell.text, cell.fontSize)
+	case "C":
+		dx = (cell.w - c.stringWidth(cell.text, cell.fontSize)) / 2
+	}
+	unitSize := cell.fontSize * 25.4 / 72 // ptをmmに変換
+	c.text(cell.x+dx, cell.y+0.5*cell.h+0.3*unitSize, cell.text, cell.fontSize)
+}
+
+// Rasterize は Render と同じ順序で、表のうち page ページ目の部分を画像に描く
+func (t *Table) Rasterize(c *pageCanvas, page int) {
+	if page < t.initialpageNum || page > t.pageNum {
+		return
+	}
+	for _, rect := range t.Rects {
+		if rect.pageNum == page && rect.style == "F" {
+			c.fillRect(rect.x, rect.y, rect.w, rect.h, rasterFill(rect.fillColor))
+		}
+	}
+	for _, cell := range t.Cells {
+		if cell.pageNum == page {
+			c.cellFormat(cell)
+		}
+	}
+	for _, text := range t.Texts {
+		if text.pageNum == page {
+			c.text(text.x, text.y, text.text, text.size)
+		}
+	}
+	for _, rect := range t.Rects {
+		if rect.pageNum == page && rect.style == "D" {
+			c.strokeRect(rect.x, rect.y, rect.w, rect.h, rect.LineWidth)
+		}
+	}
+	if t.outLine {
+		bottom := t.GetBottomLine(page)
+		top := t.GetTopLine(page)
+		if page == t.initialpageNum && bottom > 0.0 {
+			if bottom-top > 0.0 {
+				c.strokeRect(t.x_i-t.titleW, top, t.x_f-t.x_i+t.titleW, bottom-top, 0.3)
+			}
+		} else {
+			if bottom-t.margin > 0.0 {
+				c.strokeRect(t.x_i-t.titleW, t.margin, t.x_f-t.x_i+t.titleW, bottom-t.margin, 0.3)
+			}
+		}
+	}
+}
+
+// rasterizePages は描画結果を1ページずつ画像にする
+func rasterizePages(f *opentype.Font, layouts []postingLayout, pageNum int, dpi float64) []*image.RGBA {
+	var pages []*image.RGBA
+	for page := 1; page <= pageNum; page++ {
+		c := newPageCanvas(f, dpi)
+		for _, layout := range layouts {
+			for _, text := range layout.texts {
+				if text.pageNum == page {
+					c.text(text.x, text.y, text.text, text.size)
+				}
+			}
+			for _, t := range layout.tables {
+				t.Rasterize(c, page)
+			}
+			for _, img := range layout.images {
+				if img.pageNum == page {
+					img.rasterize(c)
+				}
+			}
+		}
+		pages = append(pages, c.img)
+	}
+	return pages
+}
+
+// WritePNG は画像をPNGとして書き出す
+func WritePNG(w io.Writer, img image.Image) error {
+	return png.Encode(w, img)
+}
@@ -0,0 +1,133 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// シートの読み取り範囲の上限（使用範囲が壊れていて極端に大きいファイルのため）
+const (
+	maxReadRows = 1000
+	maxReadCols = 200
+)
+
+// 読み取り範囲を指定する名前付き範囲の名前
+const postingRangeName = "求人票"
+
+// 警告に列挙するセルの数
+const maxOutsideCells = 5
+
+// truncatedWarning は読み取り範囲の上限を超えて読み込まなかった値があるときの注意事項を返す
+func truncatedWarning(sheet string) string {
+	return fmt.Sprintf("%s: %d行・%d列を超える部分は読み込みません", sheet, maxReadRows, maxReadCols)
+}
+
+// sheetReadRange はシートの読み取り範囲（行数・列数）を決める。
+// 印刷範囲、名前付き範囲「求人票」、使用範囲の順に探し、テンプレートの範囲（A1:AD48）より狭くはしない
+func sheetReadRange(fx *excelize.File, sheet string) (rows, cols int) {
+	rows, cols = maxReadRows, maxReadCols
+	if r, c, ok := definedRangeEnd(fx, sheet); ok {
+		rows, cols = r, c
+	} else if dim, err := fx.GetSheetDimension(sheet); err == nil {
+		if r, c, ok := rangeEnd(dim); ok {
+			rows, cols = r, c
+		}
+	}
+	return min(max(rows, gridRows), maxReadRows), min(max(cols, gridCols), maxReadCols)
+}
+
+// definedRangeEnd はシートの印刷範囲、なければ名前付き範囲「求人票」の右下のセル位置を返す
+func definedRangeEnd(fx *excelize.File, sheet string) (rows, cols int, ok bool) {
+	names := fx.GetDefinedName()
+	for _, target := range []string{"_xlnm.Print_Area", postingRangeName} {
+		for _, dn := range names {
+			if dn.Name != target || (dn.Scope != sheet && dn.Scope != "Workbook") {
+				continue
+			}
+			if r, c, ok := refersToEnd(dn.RefersTo, sheet); ok {
+				return r, c, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// refersToEnd は「'Sheet1'!$A$1:$AD$48」のような参照のうち、sheet 上の範囲の右下を返す（複数範囲はカンマ区切り）
+func refersToEnd(refersTo, sheet string) (rows, cols int, ok bool) {
+	for _, area := range strings.Split(refersTo, ",") {
+		i := strings.LastIndex(area, "!")
+		if i < 0 {
+			continue
+		}
+		name := strings.Trim(strings.TrimPrefix(area[:i], "="), "'")
+		if name != sheet {
+			continue
+		}
+		if r, c, found := rangeEnd(area[i+1:]); found {
+			rows, cols, ok = max(rows, r), max(cols, c), true
+		}
+	}
+	return rows, cols, ok
+}
+
+// rangeEnd は「A1:AD48」のような範囲の右下のセル位置（1始まり）を返す
+func rangeEnd(ref string) (rows, cols int, ok bool) {
+	ref = strings.ReplaceAll(ref, "$", "")
+	if i := strings.LastIndex(ref, ":"); i >= 0 {
+		ref = ref[i+1:]
+	}
+	c, r, err := excelize.CellNameToCoordinates(ref)
+	if err != nil {
+		return 0, 0, false
+	}
+	return r, c, true
+}
+
+// layoutExtent: レイアウトが参照するセルのうち最も下の行・右の列（tableData のインデックス）
+var layoutExtent = func() CellRef {
+	var ext CellRef
+	add := func(r CellRef) {
+		ext.Row = max(ext.Row, r.Row)
+		ext.Col = max(ext.Col, r.Col)
+	}
+	for _, s := range append([]Section{idSection}, postingSections...) {
+		if s.title != nil {
+			add(*s.title)
+		}
+		for _, c := range s.cells {
+			add(c.src)
+			add(c.label)
+		}
+	}
+	add(appendixRef)
+	return ext
+}()
+
+// outsideLayoutWarning はレイアウトが参照する範囲の外に入力があるセルを警告にする
+func outsideLayoutWarning(sheet string, rows [][]string) string {
+	var cells []string
+	count := 0
+	for r, row := range rows {
+		for c, v := range row {
+			// 版のセルは出力しないが、範囲外の入力とはみなさない
+			if strings.TrimSpace(v) == "" || (r <= layoutExtent.Row && c <= layoutExtent.Col) || at(r, c) == templateVersionCell {
+				continue
+			}
+			count++
+			if len(cells) < maxOutsideCells {
+				name, _ := excelize.CoordinatesToCellName(c+1, r+1)
+				cells = append(cells, name)
+			}
+		}
+	}
+	if count == 0 {
+		return ""
+	}
+	more := ""
+	if count > len(cells) {
+		more = fmt.Sprintf(" ほか%dセル", count-len(cells))
+	}
+	return fmt.Sprintf("%s: 求人票の範囲外（%s%s）に入力があります。この内容は出力されません", sheet, strings.Join(cells, ", "), more)
+}
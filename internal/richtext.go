@@ -0,0 +1,276 @@
+package internal
+
+import (
+	"image/color"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/xuri/excelize/v2"
+)
+
+// TextRun: セル内の書式付きの文字列（太字・色・下線・大きさ）
+type TextRun struct {
+	Text      string
+	Bold      bool
+	Underline bool
+	Color     string  // 文字の色（"RRGGBB"。空の場合は黒）
+	Scale     float64 // セルの文字の大きさに対する倍率（0は等倍）
+}
+
+func (r TextRun) styled() bool {
+	return r.Bold || r.Underline || r.Color != "" || (r.Scale != 0 && r.Scale != 1)
+}
+
+func (r TextRun) scale() float64 {
+	if r.Scale <= 0 {
+		return 1
+	}
+	return r.Scale
+}
+
+// runsText は書式を除いた文字列を返す
+func runsText(runs []TextRun) string {
+	var b strings.Builder
+	for _, r := range runs {
+		b.WriteString(r.Text)
+	}
+	return b.String()
+}
+
+// hasStyledRun は書式の付いた部分があるかを返す
+func hasStyledRun(runs []TextRun) bool {
+	for _, r := range runs {
+		if r.styled() {
+			return true
+		}
+	}
+	return false
+}
+
+// styledRuns は書式の付いた部分がある場合だけ runs を返す（書式がなければ通常の文字列として描く）
+func styledRuns(runs []TextRun) []TextRun {
+	if !hasStyledRun(runs) {
+		return nil
+	}
+	return runs
+}
+
+// maxRunScale は最も大きい文字の倍率を返す（行の高さに使う）
+func maxRunScale(runs []TextRun) float64 {
+	scale := 1.0
+	for _, r := range runs {
+		scale = max(scale, r.scale())
+	}
+	return scale
+}
+
+// readRichText はシートのうちテンプレートの範囲内で、書式付きの文字列があるセルを読み取る。
+// 倍率はセルの文字の大きさ（未設定の場合は11pt）に対して求める
+func readRichText(fx *excelize.File, sheet string, tableData [][]string) map[CellRef][]TextRun {
+	rich := map[CellRef][]TextRun{}
+	for r := 0; r < len(tableData) && r < gridRows; r++ {
+		for c := 0; c < len(tableData[r]) && c < gridCols; c++ {
+			if tableData[r][c] == "" {
+				continue
+			}
+			name, _ := excelize.CoordinatesToCellName(c+1, r+1)
+			runs, err := fx.GetCellRichText(sheet, name)
+			if err != nil || len(runs) == 0 {
+				continue
+			}
+			baseSize := 11.0
+			if id, err := fx.GetCellStyle(sheet, name); err == nil {
+				if s, err := fx.GetStyle(id); err == nil && s.Font != nil && s.Font.Size > 0 {
+					baseSize = s.Font.Size
+				}
+			}
+			var textRuns []TextRun
+			for _, run := range runs {
+				tr := TextRun{Text: run.Text}
+				if f := run.Font; f != nil {
+					tr.Bold = f.Bold
+					tr.Underline = f.Underline != "" && f.Underline != "none"
+					tr.Color = excelFontColor(f.Color)
+					if f.Size > 0 {
+						tr.Scale = f.Size / baseSize
+					}
+				}
+				textRuns = append(textRuns, tr)
+			}
+			// 値が表示形式などで変わっている場合は書式を使わない
+			if hasStyledRun(textRuns) && runsText(textRuns) == tableData[r][c] {
+				rich[at(r, c)] = textRuns
+			}
+		}
+	}
+	return rich
+}
+
+// excelFontColor は文字の色を "RRGGBB" で返す。黒やテーマの色は空文字にする
+func excelFontColor(c string) string {
+	c = strings.TrimPrefix(strings.ToUpper(c), "#")
+	if len(c) == 8 { // ARGB
+		c = c[2:]
+	}
+	if _, _, _, ok := parseHexColor(c); !ok || c == "000000" {
+		return ""
+	}
+	return c
+}
+
+// SplitRunsByMaxChars は SplitByMaxChars と同じ規則で、書式付きの文字列を幅に収まる行に分ける。
+// 行の途中で書式が変わる場合も、書式の区切りをまたいで1行に詰める
+func SplitRunsByMaxChars(pdf *gofpdf.Fpdf, runs []TextRun, width float64, fontSize float64) [][]TextRun {
+	var runes []rune
+	var owner []int // 各文字がどの TextRun のものか
+	for i, r := range runs {
+		for _, ch := range r.Text {
+			runes = append(runes, ch)
+			owner = append(owner, i)
+		}
+	}
+	// 文字ごとの幅（書式ごとの大きさで測る）
+	widths := make([]float64, len(runes))
+	for i, ch := range runes {
+		pdf.SetFontSize(fontSize * runs[owner[i]].scale())
+		widths[i] = pdf.GetStringWidth(string(ch))
+	}
+	pdf.SetFontSize(fontSize)
+
+	var lines [][]TextRun
+	returnCheck := false
+	for i := 0; i < len(runes); {
+		// 行頭スペースの扱いは SplitByMaxChars と同じ
+		if !returnCheck {
+			for i < len(runes) && (runes[i] == ' ' || runes[i] == '　') {
+				i++
+			}
+		}
+		if i >= len(runes) {
+			break
+		}
+
+		end := i
+		for end < len(runes) && runes[end] != '\n' {
+			end++
+		}
+		maxChars := len(runes) - i
+		accumWidth := 0.0
+		for j := i; j < len(runes); j++ {
+			if accumWidth+widths[j] > width-1 {
+				maxChars = max(j-i, 1) // 1文字も収まらない幅でも1文字ずつ進める
+				break
+			}
+			accumWidth += widths[j]
+		}
+		if i+maxChars > end {
+			maxChars = end - i
+		}
+
+		// 行内の文字を書式ごとにまとめる
+		var line []TextRun
+		for j := i; j < i+maxChars; j++ {
+			if len(line) > 0 && j > i && owner[j] == owner[j-1] {
+				line[len(line)-1].Text += string(runes[j])
+				continue
+			}
+			run := runs[owner[j]]
+			run.Text = string(runes[j])
+			line = append(line, run)
+		}
+		lines = append(lines, line)
+
+		i += maxChars
+		if i < len(runes) && runes[i] == '\n' {
+			i++
+			returnCheck = true
+		} else {
+			returnCheck = false
+		}
+	}
+	return lines
+}
+
+// drawRuns は gofpdf の CellFormat と同じ配置で、書式付きの文字列をセル内に描く
+func (t *Table) drawRuns(cell CellInfo) {
+	total := 0.0
+	for _, r := range cell.runs {
+		t.pdf.SetFontSize(cell.fontSize * r.scale())
+		total += t.pdf.GetStringWidth(r.Text)
+	}
+	margin := t.pdf.GetCellMargin()
+	x := cell.x + margin
+	switch cell.align {
+	case "R":
+		x = cell.x + cell.w - margin - total
+	case "C":
+		x = cell.x + (cell.w-total)/2
+	}
+	t.pdf.SetFontSize(cell.fontSize)
+	_, unitSize := t.pdf.GetFontSize()
+	y := cell.y + 0.5*cell.h + 0.3*unitSize
+
+	tr, tg, tb := t.pdf.GetTextColor()
+	dr, dg, db := t.pdf.GetDrawColor()
+	lineWidth := t.pdf.GetLineWidth()
+	for _, r := range cell.runs {
+		style := ""
+		if r.Underline {
+			style = "U"
+		}
+		t.pdf.SetFont(t.font, style, cell.fontSize*r.scale())
+		cr, cg, cb, ok := parseHexColor(r.Color)
+		if !ok {
+			cr, cg, cb = 0, 0, 0
+		}
+		t.pdf.SetTextColor(cr, cg, cb)
+		if r.Bold {
+			// 太字のフォントがないため、輪郭を同じ色で重ねて太く見せる
+			t.pdf.SetDrawColor(cr, cg, cb)
+			t.pdf.SetLineWidth(cell.fontSize * r.scale() * 0.01)
+			t.pdf.SetTextRenderingMode(2)
+		}
+		t.pdf.Text(x, y, r.Text)
+		if r.Bold {
+			t.pdf.SetTextRenderingMode(0)
+		}
+		x += t.pdf.GetStringWidth(r.Text)
+	}
+	t.pdf.SetFont(t.font, "", cell.fontSize)
+	t.pdf.SetTextColor(tr, tg, tb)
+	t.pdf.SetDrawColor(dr, dg, db)
+	t.pdf.SetLineWidth(lineWidth)
+}
+
+// drawRuns は Table.drawRuns と同じ配置で、書式付きの文字列を画像に描く
+func (c *pageCanvas) drawRuns(cell CellInfo) {
+	total := 0.0
+	for _, r := range cell.runs {
+		total += c.stringWidth(r.Text, cell.fontSize*r.scale())
+	}
+	x := cell.x + rasterCellMargin
+	switch cell.align {
+	case "R":
+		x = cell.x + cell.w - rasterCellMargin - total
+	case "C":
+		x = cell.x + (cell.w-total)/2
+	}
+	unitSize := cell.fontSize * 25.4 / 72 // ptをmmに変換
+	y := cell.y + 0.5*cell.h + 0.3*unitSize
+	for _, r := range cell.runs {
+		size := cell.fontSize * r.scale()
+		col := color.Color(color.Black)
+		if cr, cg, cb, ok := parseHexColor(r.Color); ok {
+			col = color.RGBA{R: uint8(cr), G: uint8(cg), B: uint8(cb), A: 255}
+		}
+		c.textColor(x, y, r.Text, size, col)
+		if r.Bold {
+			c.textColor(x+0.5/c.scale, y, r.Text, size, col)
+		}
+		w := c.stringWidth(r.Text, size)
+		if r.Underline {
+			c.fillRect(x, y+0.15*unitSize, w, max(0.05*unitSize, 1/c.scale), col)
+		}
+		x += w
+	}
+}
@@ -0,0 +1,204 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// 入力ルールの重大度
+const (
+	severityError   = "error"   // そのシートはどの形式でも出力しない
+	severityWarning = "warning" // 注意事項にする
+)
+
+// FieldRule: 項目の入力ルール。指定した条件ごとに確認する
+type FieldRule struct {
+	Field       string   `json:"field"`       // 項目（JSONキーまたは項目名）
+	Severity    string   `json:"severity"`    // "error"（そのシートを出力しない）または "warning"（注意事項にする）
+	Required    bool     `json:"required"`    // 空を許さない
+	Pattern     string   `json:"pattern"`     // 値が一致すべき正規表現（空の値は確認しない）
+	MaxLength   int      `json:"maxLength"`   // 最大文字数（0は制限なし）
+	Allowed     []string `json:"allowed"`     // 値の候補（空の場合は制限なし）
+	LessOrEqual string   `json:"lessOrEqual"` // 値がこの項目の値以下であること（金額または日付。どちらかが空なら確認しない）
+}
+
+// defaultFieldRules: ConvertOptions.Rules を指定しない場合の入力ルール。
+// 既定では出力を止めないよう、すべて warning にする（出力を止めるには error のルールを指定する）
+var defaultFieldRules = []FieldRule{
+	{Field: "jobTitle", Severity: severityWarning, Required: true},
+	{Field: "location", Severity: severityWarning, Required: true},
+	{Field: "salaryType", Severity: severityWarning, Required: true},
+	{Field: "salaryMin", Severity: severityWarning, Required: true, LessOrEqual: "salaryMax"},
+	{Field: "jobTitle", Severity: severityWarning, MaxLength: 40},
+	{Field: "employmentType", Severity: severityWarning, Required: true, Allowed: fieldChoices["employmentType"]},
+	{Field: "salaryType", Severity: severityWarning, Allowed: fieldChoices["salaryType"]},
+	{Field: "description", Severity: severityWarning, Required: true},
+	{Field: "hours", Severity: severityWarning, Required: true},
+	{Field: "holidays", Severity: severityWarning, Required: true},
+	{Field: "companyName", Severity: severityWarning, Required: true},
+	{Field: "website", Severity: severityWarning, Pattern: `^https?://\S+$`},
+	{Field: "deadline", Severity: severityWarning, LessOrEqual: "startDate"},
+}
+
+// 既定の入力ルールを返す（画面で編集するときのひな形）
+func (a *App) DefaultFieldRules() []FieldRule {
+	return defaultFieldRules
+}
+
+// fieldRule: 項目と正規表現を解決した入力ルール
+type fieldRule struct {
+	FieldRule
+	field   FieldDef
+	other   FieldDef // LessOrEqual の項目
+	pattern *regexp.Regexp
+}
+
+// compileRules は入力ルールの項目と正規表現を確認する。rules が nil の場合は既定のルールを使う
+func compileRules(rules []FieldRule) ([]fieldRule, error) {
+	if rules == nil {
+		rules = defaultFieldRules
+	}
+	var compiled []fieldRule
+	for i, r := range rules {
+		c := fieldRule{FieldRule: r}
+		var ok bool
+		if c.field, ok = fieldByHeader(r.Field); !ok {
+			return nil, fmt.Errorf("入力ルール %d: 項目 %s がありません", i+1, r.Field)
+		}
+		if r.Severity != severityError && r.Severity != severityWarning {
+			return nil, fmt.Errorf("入力ルール %d: 重大度は %s か %s にしてください", i+1, severityError, severityWarning)
+		}
+		if r.Pattern != "" {
+			re, err := regexp.Compile(r.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("入力ルール %d: 正規表現 %s が正しくありません: %w", i+1, r.Pattern, err)
+			}
+			c.pattern = re
+		}
+		if r.LessOrEqual != "" {
+			if c.other, ok = fieldByHeader(r.LessOrEqual); !ok {
+				return nil, fmt.Errorf("入力ルール %d: 項目 %s がありません", i+1, r.LessOrEqual)
+			}
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+// ruleViolation: 入力ルールに合わない値
+type ruleViolation struct {
+	severity string
+	field    FieldDef
+	message  string
+}
+
+// warning は注意事項の文（例: 「Sheet1 N15: 入力エラー: 給与下限が空です」）を返す
+func (v ruleViolation) warning(sheet string) string {
+	prefix := ""
+	if v.severity == severityError {
+		prefix = "入力エラー: "
+	}
+	return fmt.Sprintf("%s %s: %s%s", sheet, cellName(v.field.Ref), prefix, v.message)
+}
+
+// checkRules は1シートの値を入力ルールで確認する
+func checkRules(tableData [][]string, rules []fieldRule) []ruleViolation {
+	var violations []ruleViolation
+	for _, r := range rules {
+		value := strings.TrimSpace(r.field.Ref.Value(tableData))
+		add := func(format string, args ...any) {
+			violations = append(violations, ruleViolation{r.Severity, r.field, fmt.Sprintf(format, args...)})
+		}
+		if value == "" {
+			if r.Required {
+				add("%sが空です", r.field.Label)
+			}
+			continue
+		}
+		if r.pattern != nil && !r.pattern.MatchString(value) {
+			add("%s「%s」が形式（%s）に合いません", r.field.Label, value, r.Pattern)
+		}
+		if n := utf8.RuneCountInString(value); r.MaxLength > 0 && n > r.MaxLength {
+			add("%sが%d文字を超えています（%d文字）", r.field.Label, r.MaxLength, n)
+		}
+		if len(r.Allowed) > 0 && !contains(r.Allowed, value) {
+			add("%s「%s」は候補（%s）にありません", r.field.Label, value, strings.Join(r.Allowed, "、"))
+		}
+		if r.LessOrEqual != "" {
+			other := strings.TrimSpace(r.other.Ref.Value(tableData))
+			if greater(value, other) {
+				add("%s（%s）が%s（%s）を超えています", r.field.Label, value, r.other.Label, other)
+			}
+		}
+	}
+	return violations
+}
+
+// greater は a と b を日付、なければ金額として比べ、a が大きいかを返す（比べられない場合は false）
+func greater(a, b string) bool {
+	if ta, ok := parsePostingDate(a); ok {
+		if tb, ok := parsePostingDate(b); ok {
+			return ta.After(tb)
+		}
+		return false
+	}
+	va, okA := parseYen(a)
+	vb, okB := parseYen(b)
+	return okA && okB && va > vb
+}
+
+// hasRuleError は重大度 error の違反があるかを返す
+func hasRuleError(violations []ruleViolation) bool {
+	for _, v := range violations {
+		if v.severity == severityError {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRules はシートごとに入力ルールを確認し、違反を注意事項の文にする。
+// 重大度 error の違反があるシートは blocked に入れる
+func (l loadedPostings) checkRules(rules []fieldRule) (warnings []string, blocked map[int]bool) {
+	blocked = map[int]bool{}
+	for i, tableData := range l.postings {
+		violations := checkRules(tableData, rules)
+		for _, v := range violations {
+			warnings = append(warnings, v.warning(l.sheets[i]))
+		}
+		if hasRuleError(violations) {
+			blocked[i] = true
+		}
+	}
+	return warnings, blocked
+}
+
+// blockedSheets は blocked のシート名を「、」でつなぐ
+func (l loadedPostings) blockedSheets(blocked map[int]bool) string {
+	var sheets []string
+	for i, sheet := range l.sheets {
+		if blocked[i] {
+			sheets = append(sheets, sheet)
+		}
+	}
+	return strings.Join(sheets, "、")
+}
+
+// without は blocked のシートを除いた loadedPostings を返す
+func (l loadedPostings) without(blocked map[int]bool) loadedPostings {
+	var out loadedPostings
+	for i := range l.postings {
+		if blocked[i] {
+			continue
+		}
+		s := l.sheet(i)
+		out.postings = append(out.postings, s.postings...)
+		out.sheets = append(out.sheets, s.sheets...)
+		out.layouts = append(out.layouts, s.layouts...)
+		out.richText = append(out.richText, s.richText...)
+		out.pictures = append(out.pictures, s.pictures...)
+	}
+	return out
+}
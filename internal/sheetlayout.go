@@ -0,0 +1,396 @@
+package internal
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/xuri/excelize/v2"
+)
+
+// シートの書式どおりに描画するときの余白（mm）
+const (
+	sheetMarginSide   = 15.0
+	sheetMarginTop    = 15.0
+	sheetMarginBottom = 20.0
+	sheetMinFontSize  = 4.0
+)
+
+// sheetCell: シートの書式どおりに描画する1セル（結合セルは左上のセルにまとめる）
+type sheetCell struct {
+	col_i, row_i int     // 開始位置
+	col_f, row_f int     // 終了位置（結合セルの右下の次）
+	text         string  // 表示する値
+	align        string  // テキストの配置
+	border       string  // 枠線（"L"・"T"・"R"・"B" の組み合わせ。空の場合はなし）
+	lineWidth    float64 // 線の太さ
+	fillColor    string  // 塗りつぶしの色（"RRGGBB"。空の場合はなし）
+	fontSize     float64 // フォントサイズ（pt）
+}
+
+// sheetLayout: シートの結合セル・列幅・行の高さ・塗りつぶし・罫線
+type sheetLayout struct {
+	colWidths  []float64 // 列の幅（mm）
+	rowHeights []float64 // 行の高さ（mm）
+	cells      []sheetCell
+}
+
+// readSheetLayout はシートの書式を読み取る。範囲は印刷範囲、なければ値・結合セル・画像のある範囲
+func readSheetLayout(fx *excelize.File, sheet string, tableData [][]string, pictures []sheetPicture) (*sheetLayout, error) {
+	merges, err := fx.GetMergeCells(sheet)
+	if err != nil {
+		return nil, err
+	}
+	mergeEnd := map[CellRef]CellRef{}
+	covered := map[CellRef]bool{}
+	rows, cols := 0, 0
+	for _, mc := range merges {
+		c0, r0, err := excelize.CellNameToCoordinates(mc.GetStartAxis())
+		if err != nil {
+			continue
+		}
+		c1, r1, err := excelize.CellNameToCoordinates(mc.GetEndAxis())
+		if err != nil {
+			continue
+		}
+		mergeEnd[at(r0-1, c0-1)] = at(r1-1, c1-1)
+		for r := r0 - 1; r < r1; r++ {
+			for c := c0 - 1; c < c1; c++ {
+				if r != r0-1 || c != c0-1 {
+					covered[at(r, c)] = true
+				}
+			}
+		}
+		rows, cols = max(rows, r1), max(cols, c1)
+	}
+	if r, c, ok := definedRangeEnd(fx, sheet); ok {
+		rows, cols = r, c
+	} else {
+		for r, row := range tableData {
+			for c, v := range row {
+				if v != "" {
+					rows, cols = max(rows, r+1), max(cols, c+1)
+				}
+			}
+		}
+		for _, pic := range pictures {
+			rows, cols = max(rows, pic.cell.Row+1), max(cols, pic.cell.Col+1)
+		}
+	}
+	rows, cols = min(rows, maxReadRows), min(cols, maxReadCols)
+
+	layout := &sheetLayout{}
+	for c := 1; c <= cols; c++ {
+		name, _ := excelize.ColumnNumberToName(c)
+		width := 0.0
+		if visible, err := fx.GetColVisible(sheet, name); err == nil && visible {
+			if w, err := fx.GetColWidth(sheet, name); err == nil {
+				width = excelColWidthMM(w)
+			}
+		}
+		layout.colWidths = append(layout.colWidths, width)
+	}
+	for r := 1; r <= rows; r++ {
+		height := 0.0
+		if visible, err := fx.GetRowVisible(sheet, r); err == nil && visible {
+			if h, err := fx.GetRowHeight(sheet, r); err == nil {
+				height = h * 25.4 / 72 // ptをmmに変換
+			}
+		}
+		layout.rowHeights = append(layout.rowHeights, height)
+	}
+
+	styles := map[int]*excelize.Style{}
+	styleAt := func(ref CellRef) *excelize.Style {
+		name, _ := excelize.CoordinatesToCellName(ref.Col+1, ref.Row+1)
+		id, err := fx.GetCellStyle(sheet, name)
+		if err != nil {
+			return nil
+		}
+		if s, ok := styles[id]; ok {
+			return s
+		}
+		s, err := fx.GetStyle(id)
+		if err != nil {
+			s = nil
+		}
+		styles[id] = s
+		return s
+	}
+
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			ref := at(r, c)
+			if covered[ref] {
+				continue
+			}
+			end, ok := mergeEnd[ref]
+			if !ok {
+				end = ref
+			}
+			end = at(min(end.Row, rows-1), min(end.Col, cols-1))
+			cell := sheetCell{col_i: c, row_i: r, col_f: end.Col + 1, row_f: end.Row + 1, text: ref.Value(tableData), align: "L", fontSize: 11, lineWidth: 0.1}
+			// 結合セルの枠線は左上と右下のセルから取る
+			topLeft, bottomRight := styleAt(ref), styleAt(end)
+			if topLeft != nil {
+				cell.align = excelAlign(topLeft)
+				cell.fillColor = excelFillColor(topLeft)
+				if topLeft.Font != nil && topLeft.Font.Size > 0 {
+					cell.fontSize = topLeft.Font.Size
+				}
+			}
+			cell.border = excelBorder(topLeft, "left", "top") + excelBorder(bottomRight, "right", "bottom")
+			for _, s := range []*excelize.Style{topLeft, bottomRight} {
+				cell.lineWidth = max(cell.lineWidth, excelBorderWidth(s))
+			}
+			layout.cells = append(layout.cells, cell)
+		}
+	}
+	return layout, nil
+}
+
+// excelColWidthMM はExcelの列幅（標準フォントの文字数）をmmにする
+func excelColWidthMM(w float64) float64 {
+	px := float64(int((256*w + float64(int(128/7))) / 256 * 7))
+	return px * 25.4 / 96
+}
+
+func excelAlign(s *excelize.Style) string {
+	if s.Alignment == nil {
+		return "L"
+	}
+	switch s.Alignment.Horizontal {
+	case "center", "centerContinuous":
+		return "C"
+	case "right":
+		return "R"
+	}
+	return "L"
+}
+
+// excelFillColor は単色の塗りつぶしの色を "RRGGBB" で返す（テーマの色などは扱わない）
+func excelFillColor(s *excelize.Style) string {
+	if s.Fill.Type != "pattern" || s.Fill.Pattern != 1 || len(s.Fill.Color) == 0 {
+		return ""
+	}
+	c := strings.TrimPrefix(strings.ToUpper(s.Fill.Color[0]), "#")
+	if len(c) == 8 { // ARGB
+		c = c[2:]
+	}
+	if _, _, _, ok := parseHexColor(c); !ok || c == "FFFFFF" {
+		return ""
+	}
+	return c
+}
+
+// excelBorder は sides のうち罫線のある辺を gofpdf の枠線指定（"L"・"T"・"R"・"B"）で返す
+func excelBorder(s *excelize.Style, sides ...string) string {
+	if s == nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, side := range sides {
+		for _, border := range s.Border {
+			if border.Type == side && border.Style > 0 {
+				b.WriteString(strings.ToUpper(side[:1]))
+				break
+			}
+		}
+	}
+	return b.String()
+}
+
+// excelBorderWidth は罫線の種類（細線・中線・太線）を線の太さにする
+func excelBorderWidth(s *excelize.Style) float64 {
+	width := 0.1
+	if s == nil {
+		return width
+	}
+	for _, border := range s.Border {
+		switch border.Style {
+		case 2, 8, 10, 12: // 中線
+			width = max(width, 0.3)
+		case 5, 6: // 太線・二重線
+			width = max(width, 0.5)
+		}
+	}
+	return width
+}
+
+// parseHexColor は "RRGGBB" を RGB に戻す
+func parseHexColor(s string) (r, g, b int, ok bool) {
+	if len(s) != 6 {
+		return 0, 0, 0, false
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	return int(v >> 16), int(v >> 8 & 0xff), int(v & 0xff), true
+}
+
+// sheetPageBreaks はページに収まるように行を分ける。結合セルの途中では分けない
+func (l *sheetLayout) sheetPageBreaks(height, scale float64) [][2]int {
+	// 行 r の上で分けられるか
+	breakable := make([]bool, len(l.rowHeights)+1)
+	for i := range breakable {
+		breakable[i] = true
+	}
+	for _, c := range l.cells {
+		for r := c.row_i + 1; r < c.row_f; r++ {
+			breakable[r] = false
+		}
+	}
+
+	var pages [][2]int
+	start, used, lastBreak := 0, 0.0, 0
+	for r, h := range l.rowHeights {
+		if breakable[r] {
+			lastBreak = r
+		}
+		if used+h*scale > height && lastBreak > start {
+			pages = append(pages, [2]int{start, lastBreak})
+			start = lastBreak
+			used = 0
+			for _, h := range l.rowHeights[start:r] {
+				used += h * scale
+			}
+		}
+		used += h * scale
+	}
+	return append(pages, [2]int{start, len(l.rowHeights)})
+}
+
+// renderSheetLayout はシートの書式どおりに pdf の現在のページから描画する。
+// 幅がページに収まらない場合は全体を縮小する。画像は貼り付けられたセルの左上に置く
+func renderSheetLayout(pdf *gofpdf.Fpdf, l *sheetLayout, pictures []sheetPicture) postingLayout {
+	var layout postingLayout
+	pdf.SetFont("IPA", "", 11)
+	pageW, pageH := pdf.GetPageSize()
+
+	totalW := 0.0
+	for _, w := range l.colWidths {
+		totalW += w
+	}
+	scale := 1.0
+	if totalW > pageW-2*sheetMarginSide {
+		scale = (pageW - 2*sheetMarginSide) / totalW
+	}
+
+	for page, rows := range l.sheetPageBreaks(pageH-sheetMarginTop-sheetMarginBottom, scale) {
+		if page != 0 {
+			pdf.AddPage()
+		}
+		t := NewTable(pdf, sheetMarginSide, sheetMarginTop, sheetMarginSide+totalW*scale, sheetMarginTop, len(l.colWidths), rows[1]-rows[0], "IPA", 11*scale, 4.5, "1")
+		// 列の幅・行の高さはシートの値で先に決めておく
+		x := sheetMarginSide
+		for i, w := range l.colWidths {
+			t.Xs[i] = x
+			x += w * scale
+		}
+		t.Xs[len(l.colWidths)] = x
+		y := sheetMarginTop
+		for _, h := range l.rowHeights[rows[0]:rows[1]] {
+			y += h * scale
+			t.Ys = append(t.Ys, y)
+			t.Rows = append(t.Rows, Row{y: y, pageNum: t.pageNum})
+		}
+
+		for _, c := range l.cells {
+			if c.row_i < rows[0] || c.row_i >= rows[1] {
+				continue
+			}
+			c.row_i -= rows[0]
+			c.row_f -= rows[0]
+			fillSheetCell(t, c, scale)
+		}
+		t.Render(false)
+		// 出力できなかったセルは、事前チェックで報告できるようシート上の行にする
+		for i := range t.problems {
+			t.problems[i].row_i += rows[0]
+		}
+		layout.tables = append(layout.tables, t)
+
+		for i := range pictures {
+			pic := &pictures[i]
+			if pic.cell.Row < rows[0] || pic.cell.Row >= rows[1] || pic.cell.Col >= len(l.colWidths) {
+				continue
+			}
+			x, y := t.Xs[pic.cell.Col], t.Ys[pic.cell.Row-rows[0]]
+			w, h := fitSize(pic.width*scale, pic.height*scale, pageW-sheetMarginSide-x, pageH-sheetMarginBottom-y)
+			p := placedImage{x: x, y: y, w: w, h: h, pageNum: pdf.PageNo(), pic: pic}
+			drawPicture(pdf, p)
+			layout.images = append(layout.images, p)
+		}
+	}
+	return layout
+}
+
+// fillSheetCell は表に1セルを配置する。1行に収まらない値は折り返し、セルの高さに収まるまで文字を小さくする
+func fillSheetCell(t *Table, c sheetCell, scale float64) {
+	w := t.Xs[c.col_f] - t.Xs[c.col_i]
+	h := t.Ys[c.row_f] - t.Ys[c.row_i]
+	if w <= 0 || h <= 0 {
+		return
+	}
+	fontSize := max(c.fontSize*scale, sheetMinFontSize)
+	cells, rects := len(t.Cells), len(t.Rects)
+
+	// 最小の文字でも1文字分の幅がないセルは値を出さず、枠線と塗りつぶしだけにする
+	t.pdf.SetFontSize(sheetMinFontSize)
+	if text := strings.TrimSpace(c.text); text != "" && t.pdf.GetStringWidth(string([]rune(text)[0])) > w {
+		t.problems = append(t.problems, layoutProblem{c.col_i, c.row_i, issueTooWide, c.text})
+		c.text = ""
+	}
+
+	t.pdf.SetFontSize(fontSize)
+	if !strings.Contains(c.text, "\n") && t.pdf.GetStringWidth(c.text) <= w {
+		t.SetCell(c.col_i, c.row_i, c.col_f, c.row_f, c.text, c.align, c.fillColor != "", fontSize, "", c.lineWidth, h)
+	} else {
+		text := c.text
+		for {
+			t.pdf.SetFontSize(fontSize)
+			_, unitSize := t.pdf.GetFontSize()
+			lines := SplitByMaxChars(t.pdf, c.text, w, fontSize)
+			if float64(len(lines))*unitSize <= h {
+				break
+			}
+			if fontSize <= sheetMinFontSize {
+				// 最小の文字でも収まらない場合は、収まる行までにする（次のページに送らないため）
+				text = strings.Join(lines[:max(int(h/unitSize), 1)], "\n")
+				t.problems = append(t.problems, layoutProblem{c.col_i, c.row_i, issueClipped, c.text})
+				break
+			}
+			fontSize = max(fontSize-0.5, sheetMinFontSize)
+		}
+		t.SetMultiRowCell(c.col_i, c.row_i, c.col_f, c.row_f, text, c.align, c.fillColor != "", fontSize, false)
+	}
+
+	// 配置したセルにシートの枠線と塗りつぶしの色を付ける（複数行セルの枠線は空のセルで描く）
+	for i := cells; i < len(t.Cells); i++ {
+		t.Cells[i].fillColor = c.fillColor
+		if t.Cells[i].border == "1" {
+			t.Cells[i].border = borderOrNone(c.border)
+		}
+	}
+	var kept []RectInfo
+	for _, r := range t.Rects[rects:] {
+		if r.style == "D" {
+			t.Cells = append(t.Cells, CellInfo{x: r.x, y: r.y, w: r.w, h: r.h, pageNum: r.pageNum, border: borderOrNone(c.border), LineWidth: c.lineWidth, col_i: c.col_i, row_i: c.row_i, col_f: c.col_f, row_f: c.row_f})
+			continue
+		}
+		r.fillColor = c.fillColor
+		kept = append(kept, r)
+	}
+	t.Rects = append(t.Rects[:rects], kept...)
+}
+
+func borderOrNone(border string) string {
+	if border == "" {
+		return "0"
+	}
+	if border == "LTRB" {
+		return "1"
+	}
+	return border
+}
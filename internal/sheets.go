@@ -0,0 +1,96 @@
+package internal
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// SheetSelection: 変換するシートの選び方（指定した条件をすべて満たすシートを変換する）
+type SheetSelection struct {
+	VisibleOnly bool     `json:"visibleOnly"` // 非表示のシート（補助の表など）を除く
+	Pattern     string   `json:"pattern"`     // シート名のパターン（「求人*」のようなワイルドカード。空の場合は条件にしない）
+	Names       []string `json:"names"`       // 変換するシート名（空の場合は条件にしない）
+}
+
+// validate はシート名のパターンを確認する
+func (s SheetSelection) validate() error {
+	if _, err := path.Match(s.Pattern, ""); err != nil {
+		return fmt.Errorf("シート名のパターン %s が正しくありません", s.Pattern)
+	}
+	return nil
+}
+
+// selects はシートが変換の対象かを返す
+func (s SheetSelection) selects(wb Workbook, sheet string) bool {
+	if s.VisibleOnly && !wb.SheetVisible(sheet) {
+		return false
+	}
+	if s.Pattern != "" {
+		if ok, _ := path.Match(s.Pattern, sheet); !ok {
+			return false
+		}
+	}
+	if len(s.Names) > 0 {
+		found := false
+		for _, name := range s.Names {
+			if strings.TrimSpace(name) == sheet {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// sheet は index 番目のシートだけを持つ loadedPostings を返す
+func (l loadedPostings) sheet(index int) loadedPostings {
+	return loadedPostings{
+		postings: l.postings[index : index+1],
+		sheets:   l.sheets[index : index+1],
+		layouts:  l.layouts[index : index+1],
+		richText: l.richText[index : index+1],
+		pictures: l.pictures[index : index+1],
+	}
+}
+
+// sheetPDFFileNames はシートごとのPDFのファイル名を返す。
+// 入力ファイル名（source）と職種・勤務地から名前を付け、職種・勤務地がどちらも空の場合や同じ名前になる場合はシート名を付け加える
+func sheetPDFFileNames(source string, loaded loadedPostings) []string {
+	names := make([]string, len(loaded.postings))
+	used := map[string]bool{}
+	for i, tableData := range loaded.postings {
+		p := NewJobPosting(tableData)
+		base := joinFileName("求人票", sourceBaseName(source), p.JobTitle, p.Location)
+		if safeFileName(p.JobTitle) == "" && safeFileName(p.Location) == "" {
+			base = joinFileName("求人票", sourceBaseName(source), loaded.sheets[i])
+		} else if used[base] {
+			base = joinFileName(base, loaded.sheets[i])
+		}
+		name := base
+		for n := 2; used[name]; n++ {
+			name = fmt.Sprintf("%s_%d", base, n)
+		}
+		used[name] = true
+		names[i] = name + ".pdf"
+	}
+	return names
+}
+
+// saveSheetPDFs は入力ファイル source のシートごとに求人票のPDFを outDir に保存し、保存したファイルのパスを返す
+func saveSheetPDFs(fontPath, source string, loaded loadedPostings, imageSlot, outDir string) ([]string, error) {
+	var paths []string
+	for i, name := range sheetPDFFileNames(source, loaded) {
+		pdf, _ := buildPostingPDF(fontPath, loaded.sheet(i), imageSlot)
+		pdfPath := uniquePath(filepath.Join(outDir, name))
+		if err := pdf.OutputFileAndClose(pdfPath); err != nil {
+			return paths, fmt.Errorf("シート %s: %w", loaded.sheets[i], err)
+		}
+		paths = append(paths, pdfPath)
+	}
+	return paths, nil
+}
@@ -0,0 +1,221 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// テンプレートの版とみなすのに必要な、ラベルが一致する割合
+const minTemplateMatch = 0.5
+
+// templateVersionCell: テンプレートの版を書くセル（AD1。例:「テンプレート版: 2」）。
+// 入力用テンプレート（WriteBlankTemplate）はこのセルに版を書き出す
+var templateVersionCell = at(0, gridCols-1)
+
+var templateVersionPattern = regexp.MustCompile(`テンプレート版[:：]?(\d+)`)
+
+// templateVersion: テンプレートの版と、その版の項目マッピング
+type templateVersion struct {
+	number int // 版のセルに書く番号
+	name   string
+	// 版を見分けるラベル（セル位置 → 表記の候補）。
+	// 会社ごとに言い回しを変えやすい項目は避け、表のタイトルと主な項目だけにする
+	labels map[CellRef][]string
+	// 現行テンプレートと値のセル位置が異なる項目（JSONキー → この版のセル位置）。
+	// この版にない項目は、範囲外のセル位置（at(-1, -1)）にする
+	moved map[string]CellRef
+}
+
+// templateVersions: 読み込めるテンプレートの版（新しい順）。
+// 古い版を読み込めるようにするには、その版のラベルと、値の位置が変わった項目だけを追加する
+var templateVersions = []templateVersion{
+	{
+		number: 2,
+		name:   "現行",
+		labels: map[CellRef][]string{
+			at(3, 0):  {"募集要項"},
+			at(12, 0): {"勤務条件"},
+			at(23, 0): {"選考"},
+			at(26, 0): {"企業情報", "会社情報", "会社概要"},
+			at(33, 0): {"その他"},
+			at(2, 24): {"求人番号", "求人No"},
+			at(3, 1):  {"職種"},
+			at(3, 21): {"雇用形態"},
+			at(5, 1):  {"契約期間"},
+			at(8, 1):  {"仕事内容", "業務内容"},
+			at(9, 1):  {"応募資格"},
+			at(12, 1): {"勤務地", "勤務先"},
+			at(14, 1): {"給与", "賃金"},
+			at(16, 1): {"勤務時間", "就業時間"},
+			at(18, 1): {"休日", "休暇"},
+			at(26, 1): {"会社名", "社名", "企業名"},
+		},
+	},
+	{
+		// 2023年版: 職種のふりがなと求人番号の欄がなく、募集要項の各行が現行より1行上にある
+		number: 1,
+		name:   "2023年版",
+		labels: map[CellRef][]string{
+			at(3, 0):  {"募集要項"},
+			at(12, 0): {"勤務条件"},
+			at(23, 0): {"選考"},
+			at(26, 0): {"企業情報", "会社情報", "会社概要"},
+			at(33, 0): {"その他"},
+			at(3, 1):  {"職種"},
+			at(3, 21): {"雇用形態"},
+			at(4, 1):  {"契約期間"},
+			at(7, 1):  {"仕事内容", "業務内容"},
+			at(8, 1):  {"応募資格"},
+			at(12, 1): {"勤務地", "勤務先"},
+			at(14, 1): {"給与", "賃金"},
+			at(16, 1): {"勤務時間", "就業時間"},
+			at(18, 1): {"休日", "休暇"},
+			at(26, 1): {"会社名", "社名", "企業名"},
+		},
+		moved: map[string]CellRef{
+			"id":             at(-1, -1),
+			"jobTitleKana":   at(-1, -1),
+			"jobTitle":       at(3, 2),
+			"contractPeriod": at(4, 2),
+			"trialPeriod":    at(4, 13),
+			"openings":       at(4, 24),
+			"industry":       at(5, 2),
+			"jobCategory":    at(5, 13),
+			"department":     at(6, 2),
+			"description":    at(7, 2),
+			"requirements":   at(8, 2),
+			"preferred":      at(9, 2),
+		},
+	},
+}
+
+// versionCellText は版のセルに書く文字列を返す
+func (v templateVersion) versionCellText() string {
+	return fmt.Sprintf("テンプレート版: %d", v.number)
+}
+
+// normalizeLabel はラベルの比較のため、空白と改行を取り除く
+func normalizeLabel(s string) string {
+	return strings.Join(strings.Fields(strings.ReplaceAll(s, "　", " ")), "")
+}
+
+// match はラベルが一致する割合を返す。「給与（月給）」のように補足を付けたラベルも一致とみなす
+func (v templateVersion) match(tableData [][]string) float64 {
+	if len(v.labels) == 0 {
+		return 0
+	}
+	matched := 0
+	for ref, alts := range v.labels {
+		got := normalizeLabel(ref.Value(tableData))
+		for _, alt := range alts {
+			if got != "" && strings.Contains(got, normalizeLabel(alt)) {
+				matched++
+				break
+			}
+		}
+	}
+	return float64(matched) / float64(len(v.labels))
+}
+
+// migrate は tableData をこの版の項目マッピングから現行テンプレートの表に移し替え、
+// 移し替えたセルの対応（この版のセル位置 → 現行のセル位置）も返す。現行の版はそのまま返す
+func (v templateVersion) migrate(tableData [][]string) ([][]string, map[CellRef]CellRef) {
+	if len(v.moved) == 0 {
+		return tableData, nil
+	}
+	grid := BlankGrid()
+	refs := map[CellRef]CellRef{}
+	for _, f := range postingFields {
+		src, ok := v.moved[f.Key]
+		if !ok {
+			src = f.Ref
+		}
+		grid[f.Ref.Row][f.Ref.Col] = src.Value(tableData)
+		refs[src] = f.Ref
+	}
+	return grid, refs
+}
+
+// versionFromCell は版のセル（「テンプレート版: 2」など）から版を返す。
+// 版のセルがない場合は found が false、書かれた番号の版がない場合は ok が false
+func versionFromCell(tableData [][]string) (version templateVersion, found, ok bool) {
+	m := templateVersionPattern.FindStringSubmatch(normalizeLabel(templateVersionCell.Value(tableData)))
+	if m == nil {
+		return version, false, false
+	}
+	n, _ := strconv.Atoi(m[1])
+	for _, v := range templateVersions {
+		if v.number == n {
+			return v, true, true
+		}
+	}
+	return version, true, false
+}
+
+// detectTemplate は版のセルがあればその版を、なければラベルが最も多く一致するテンプレートの版を返す。
+// どの版とも minTemplateMatch 以上一致しない場合は ok が false
+func detectTemplate(tableData [][]string) (version templateVersion, ok bool) {
+	if v, found, ok := versionFromCell(tableData); found && ok {
+		return v, true
+	}
+	best := -1.0
+	for _, v := range templateVersions {
+		if m := v.match(tableData); m > best {
+			version, best = v, m
+		}
+	}
+	return version, best >= minTemplateMatch
+}
+
+// migrateTemplate はシートのテンプレートの版を判別し、現行テンプレートの表にする。
+// 古い版の場合は移し替えたセルの対応（remapRichText・remapPictures に使う）も返す。
+// warnings は判別の結果（未知の版の番号、古い版として読み込んだ、またはどの版とも一致しない）
+func migrateTemplate(sheet string, tableData [][]string) (grid [][]string, refs map[CellRef]CellRef, warnings []string) {
+	if _, found, ok := versionFromCell(tableData); found && !ok {
+		warnings = append(warnings, fmt.Sprintf("%s: %s「%s」の版は未知のため、ラベルから判別します",
+			sheet, cellName(templateVersionCell), templateVersionCell.Value(tableData)))
+	}
+	version, ok := detectTemplate(tableData)
+	if !ok {
+		warnings = append(warnings, fmt.Sprintf("%s: 既知のテンプレートと一致しません。項目の位置が異なる場合、正しく出力されません", sheet))
+		return tableData, nil, warnings
+	}
+	grid, refs = version.migrate(tableData)
+	if refs != nil {
+		warnings = append(warnings, fmt.Sprintf("%s: %sのテンプレートとして読み込みました", sheet, version.name))
+	}
+	return grid, refs, warnings
+}
+
+// remapRichText は書式付きの文字列のセル位置を移し替える（移し替えないセルは除く）
+func remapRichText(rich map[CellRef][]TextRun, refs map[CellRef]CellRef) map[CellRef][]TextRun {
+	if refs == nil {
+		return rich
+	}
+	remapped := map[CellRef][]TextRun{}
+	for ref, runs := range rich {
+		if to, ok := refs[ref]; ok {
+			remapped[to] = runs
+		}
+	}
+	return remapped
+}
+
+// remapPictures は画像の左上のセル位置を移し替える。値のセル以外に貼り付けた画像は、元の位置に置けない画像にする
+func remapPictures(pictures []sheetPicture, refs map[CellRef]CellRef) []sheetPicture {
+	if refs == nil {
+		return pictures
+	}
+	remapped := make([]sheetPicture, len(pictures))
+	for i, pic := range pictures {
+		to, ok := refs[pic.cell]
+		if !ok {
+			to = at(-1, -1)
+		}
+		pic.cell = to
+		remapped[i] = pic
+	}
+	return remapped
+}
@@ -0,0 +1,206 @@
+package internal
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// 値のセルから項目マッピングへの対応（文字数制限の指定に使う）
+var fieldByRef = func() map[CellRef]FieldDef {
+	m := map[CellRef]FieldDef{}
+	for _, f := range postingFields {
+		m[f.Ref] = f
+	}
+	return m
+}()
+
+var blankLinesPattern = regexp.MustCompile(`\n{3,}`)
+
+// normalizeText は改行コードをLFにそろえ、行末の空白と連続する空行を取り除く
+func normalizeText(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(strings.ReplaceAll(line, "\t", " "), " 　")
+	}
+	s = strings.Join(lines, "\n")
+	return strings.Trim(blankLinesPattern.ReplaceAllString(s, "\n\n"), "\n")
+}
+
+// Markdownで書式になる記号（行中のどこでも意味を持つもの）
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "~", `\~`,
+	"[", `\[`, "]", `\]`, "<", `\<`, ">", `\>`, "|", `\|`, "#", `\#`,
+)
+
+// 行頭にあると箇条書き・番号付きリスト・見出しの下線・区切り線になるもの
+var markdownLineStartPattern = regexp.MustCompile(`(?m)^([ 　]*)([-+=]|\d+[.)])`)
+
+// escapeMarkdown は値がMarkdownの書式として解釈されないよう記号をエスケープする
+func escapeMarkdown(s string) string {
+	s = markdownEscaper.Replace(s)
+	return markdownLineStartPattern.ReplaceAllStringFunc(s, func(m string) string {
+		sub := markdownLineStartPattern.FindStringSubmatch(m)
+		mark := sub[2]
+		if n := len(mark); n > 1 {
+			return sub[1] + mark[:n-1] + `\` + mark[n-1:]
+		}
+		return sub[1] + `\` + mark
+	})
+}
+
+// charLimit は項目の文字数制限を返す。JSONキーと項目名のどちらでも指定できる（0は制限なし）
+func charLimit(limits map[string]int, f FieldDef) int {
+	if n, ok := limits[f.Key]; ok {
+		return n
+	}
+	return limits[f.Label]
+}
+
+// truncateText は文字数（ルーン数）が limit を超える場合に末尾を「…」にして切り詰める
+func truncateText(s string, limit int) (string, bool) {
+	runes := []rune(s)
+	if limit <= 0 || len(runes) <= limit {
+		return s, false
+	}
+	return strings.TrimRight(string(runes[:limit-1]), " 　\n") + "…", true
+}
+
+// textWriter: テキスト・Markdown形式での書き出し
+type textWriter struct {
+	w        *bufio.Writer
+	markdown bool
+	limits   map[string]int
+	warnings []string
+}
+
+// value はセルの値を整えて、文字数制限を超えた場合は切り詰める
+func (t *textWriter) value(posting int, ref CellRef, tableData [][]string) string {
+	v := normalizeText(ref.Value(tableData))
+	f, ok := fieldByRef[ref]
+	if !ok {
+		return v
+	}
+	limit := charLimit(t.limits, f)
+	v, truncated := truncateText(v, limit)
+	if truncated {
+		t.warnings = append(t.warnings, fmt.Sprintf("%d枚目: %sが%d文字を超えるため切り詰めました", posting+1, f.Label, limit))
+	}
+	return v
+}
+
+// item は項目名と値を1項目分書き出す。値が複数行の場合は項目名の次の行から書く
+func (t *textWriter) item(label string, values []string) {
+	text := strings.Join(values, "\n")
+	if t.markdown {
+		label = escapeMarkdown(label)
+		text = strings.ReplaceAll(escapeMarkdown(text), "\n", "  \n")
+	}
+	switch {
+	case label == "":
+		fmt.Fprintf(t.w, "%s\n", text)
+	case t.markdown && strings.Contains(text, "\n"):
+		fmt.Fprintf(t.w, "**%s**  \n%s\n", label, text)
+	case t.markdown:
+		fmt.Fprintf(t.w, "**%s**：%s\n", label, text)
+	case strings.Contains(text, "\n"):
+		fmt.Fprintf(t.w, "【%s】\n%s\n", label, text)
+	default:
+		fmt.Fprintf(t.w, "【%s】%s\n", label, text)
+	}
+	if t.markdown {
+		fmt.Fprintf(t.w, "\n")
+	}
+}
+
+// posting は1シート分の求人票を書き出す。値のない項目は省略する
+func (t *textWriter) posting(i int, tableData [][]string) {
+	id := idSection.cells[1].src.Value(tableData)
+	if t.markdown {
+		fmt.Fprintf(t.w, "# 求人票\n\n")
+		if id != "" {
+			fmt.Fprintf(t.w, "%s %s\n\n", escapeMarkdown(idSection.cells[0].src.Value(tableData)), escapeMarkdown(id))
+		}
+	} else {
+		fmt.Fprintf(t.w, "求人票\n")
+		if id != "" {
+			fmt.Fprintf(t.w, "%s %s\n", idSection.cells[0].src.Value(tableData), id)
+		}
+	}
+
+	for _, s := range postingSections {
+		type textItem struct {
+			label  string
+			values []string
+		}
+		var items []textItem
+		for _, refs := range s.itemRefs() {
+			var values []string
+			for _, ref := range refs.values {
+				if v := t.value(i, ref, tableData); v != "" {
+					values = append(values, v)
+				}
+			}
+			if len(values) > 0 {
+				items = append(items, textItem{strings.ReplaceAll(normalizeText(refs.label.Value(tableData)), "\n", ""), values})
+			}
+		}
+		if len(items) == 0 {
+			continue
+		}
+
+		title := ""
+		if s.title != nil {
+			title = strings.ReplaceAll(normalizeText(s.title.Value(tableData)), "\n", "")
+		}
+		switch {
+		case t.markdown && title != "":
+			fmt.Fprintf(t.w, "## %s\n\n", escapeMarkdown(title))
+		case !t.markdown && title != "":
+			fmt.Fprintf(t.w, "\n■%s\n", title)
+		case !t.markdown:
+			fmt.Fprintf(t.w, "\n")
+		}
+		for _, item := range items {
+			t.item(item.label, item.values)
+		}
+	}
+
+	if appendix := t.value(i, appendixRef, tableData); appendix != "" {
+		if t.markdown {
+			fmt.Fprintf(t.w, "%s\n", strings.ReplaceAll(escapeMarkdown(appendix), "\n", "  \n"))
+		} else {
+			fmt.Fprintf(t.w, "\n%s\n", appendix)
+		}
+	}
+}
+
+func writePostingText(w io.Writer, postings [][][]string, markdown bool, limits map[string]int) ([]string, error) {
+	t := &textWriter{w: bufio.NewWriter(w), markdown: markdown, limits: limits}
+	for i, tableData := range postings {
+		if i != 0 {
+			if markdown {
+				fmt.Fprintf(t.w, "\n---\n\n")
+			} else {
+				fmt.Fprintf(t.w, "\n%s\n\n", strings.Repeat("-", 40))
+			}
+		}
+		t.posting(i, tableData)
+	}
+	return t.warnings, t.w.Flush()
+}
+
+// WritePostingText はシートごとの求人票をプレーンテキストで書き出す。
+// limits は項目ごとの文字数制限で、切り詰めた項目は warnings に入れる
+func WritePostingText(w io.Writer, postings [][][]string, limits map[string]int) ([]string, error) {
+	return writePostingText(w, postings, false, limits)
+}
+
+// WritePostingMarkdown はシートごとの求人票をMarkdownで書き出す
+func WritePostingMarkdown(w io.Writer, postings [][][]string, limits map[string]int) ([]string, error) {
+	return writePostingText(w, postings, true, limits)
+}
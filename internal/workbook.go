@@ -0,0 +1,219 @@
+package internal
+
+import (
+	"archive/zip"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// テンプレートの読み取り範囲（A1:AD48）
+const (
+	gridRows = 48
+	gridCols = 30
+)
+
+// Workbook: 入力ファイルをシートごとの表（tableData）として読み取る
+type Workbook interface {
+	SheetList() []string
+	SheetVisible(sheet string) bool // 非表示のシートは false
+	Grid(sheet string) ([][]string, error)
+	Warnings() []string // 読み込み中の注意事項（計算できなかった数式など）
+	Close() error
+}
+
+// xlsxWorkbook: excelize で開いたExcelファイル
+type xlsxWorkbook struct {
+	a        *App
+	fx       *excelize.File
+	warnings []string
+	list     bool // 求人の一覧として読む（差し込み）
+}
+
+func (w *xlsxWorkbook) SheetList() []string {
+	return w.fx.GetSheetList()
+}
+
+func (w *xlsxWorkbook) SheetVisible(sheet string) bool {
+	visible, err := w.fx.GetSheetVisible(sheet)
+	return err != nil || visible
+}
+
+func (w *xlsxWorkbook) Grid(sheet string) ([][]string, error) {
+	grid, warnings, err := w.a.loadData(sheet, w.fx, w.list)
+	w.warnings = append(w.warnings, warnings...)
+	return grid, err
+}
+
+func (w *xlsxWorkbook) Warnings() []string {
+	return w.warnings
+}
+
+func (w *xlsxWorkbook) Close() error {
+	return w.fx.Close()
+}
+
+// gridWorkbook: 読み込み済みの表をシート名の順に持つ（CSV・ODSなど）
+type gridWorkbook struct {
+	sheets   []string
+	grids    map[string][][]string
+	hidden   map[string]bool
+	warnings []string
+}
+
+func newGridWorkbook() *gridWorkbook {
+	return &gridWorkbook{grids: map[string][][]string{}, hidden: map[string]bool{}}
+}
+
+func (w *gridWorkbook) add(sheet string, grid [][]string) {
+	w.sheets = append(w.sheets, sheet)
+	w.grids[sheet] = grid
+}
+
+// addTruncated は読み取り範囲の上限を超えて読み込まなかった値があることを注意事項にする
+func (w *gridWorkbook) addTruncated(sheet string) {
+	w.warnings = append(w.warnings, truncatedWarning(sheet))
+}
+
+func (w *gridWorkbook) SheetList() []string {
+	return w.sheets
+}
+
+func (w *gridWorkbook) SheetVisible(sheet string) bool {
+	return !w.hidden[sheet]
+}
+
+func (w *gridWorkbook) Grid(sheet string) ([][]string, error) {
+	grid, ok := w.grids[sheet]
+	if !ok {
+		return nil, fmt.Errorf("シート %s がありません", sheet)
+	}
+	return grid, nil
+}
+
+func (w *gridWorkbook) Warnings() []string {
+	return w.warnings
+}
+
+func (w *gridWorkbook) Close() error {
+	return nil
+}
+
+// toGrid は行ごとの値を loadData と同じ形の表にする。列数はテンプレートの範囲（AD列）より狭くはせず、
+// 行・列は maxReadRows・maxReadCols までにする。上限を超える値があった場合は truncated が true
+func toGrid(rows [][]string) (grid [][]string, truncated bool) {
+	cols := gridCols
+	for i, row := range rows {
+		for c, v := range row {
+			if v == "" {
+				continue
+			}
+			if i >= maxReadRows || c >= maxReadCols {
+				truncated = true
+			} else {
+				cols = max(cols, c+1)
+			}
+		}
+	}
+	for i, row := range rows {
+		if i >= maxReadRows {
+			break
+		}
+		rowData := make([]string, cols)
+		copy(rowData, row)
+		grid = append(grid, rowData)
+	}
+	return grid, truncated
+}
+
+// decodeFileData はフロントエンドから受け取ったBase64のファイル内容を戻す
+func decodeFileData(f FileData) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(f.Data)
+	if err != nil {
+		return nil, fmt.Errorf("%s のデコードに失敗: %w", f.Name, err)
+	}
+	return data, nil
+}
+
+// パスワード付きのファイルを開けなかったときのエラー
+var (
+	ErrPasswordRequired = errors.New("パスワードで保護されています。パスワードを入力してください")
+	ErrWrongPassword    = errors.New("パスワードが違います")
+)
+
+// openWorkbook は拡張子に応じてファイルを開く
+func (a *App) openWorkbook(f FileData, passwords []string) (Workbook, error) {
+	switch strings.ToLower(filepath.Ext(f.Name)) {
+	case ".csv", ".tsv":
+		data, err := decodeFileData(f)
+		if err != nil {
+			return nil, err
+		}
+		w, err := readDelimited(f.Name, data)
+		if err != nil {
+			return nil, fmt.Errorf("%s のCSV読込に失敗: %w", f.Name, err)
+		}
+		return w, nil
+	case ".ods":
+		data, err := decodeFileData(f)
+		if err != nil {
+			return nil, err
+		}
+		w, err := readODS(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s のODS読込に失敗: %w", f.Name, err)
+		}
+		return w, nil
+	}
+
+	// .xls と .xlsx/.xlsm は拡張子ではなく中身で判別する（拡張子を付け替えたファイルがあるため）
+	data, err := decodeFileData(f)
+	if err != nil {
+		return nil, err
+	}
+	if isOLEFile(data) {
+		w, err := readXLS(data)
+		if errors.Is(err, errWorkbookEncrypted) {
+			return a.openEncryptedXLSX(f, passwords)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s のExcel読込に失敗: %w", f.Name, err)
+		}
+		return w, nil
+	}
+	fx, _, err := a.loadCSV(f)
+	if err != nil {
+		if errors.Is(err, zip.ErrFormat) {
+			return nil, fmt.Errorf("%s のExcel読込に失敗: %w", f.Name, errWorkbookCorrupted)
+		}
+		return nil, fmt.Errorf("CSVファイルの読み込みに失敗: %w", err)
+	}
+	return &xlsxWorkbook{a: a, fx: fx}, nil
+}
+
+// openEncryptedXLSX はパスワード付きのxlsx/xlsmを、passwords を順に試して開く
+func (a *App) openEncryptedXLSX(f FileData, passwords []string) (Workbook, error) {
+	tried := map[string]bool{}
+	for _, password := range passwords {
+		if password == "" || tried[password] {
+			continue
+		}
+		tried[password] = true
+		fx, _, err := a.loadCSV(f, excelize.Options{Password: password})
+		if err == nil {
+			return &xlsxWorkbook{a: a, fx: fx}, nil
+		}
+		// パスワードが違うと、復号した内容をxlsxとして読めずにどちらかのエラーになる
+		if !errors.Is(err, excelize.ErrWorkbookPassword) && !errors.Is(err, excelize.ErrWorkbookFileFormat) {
+			return nil, fmt.Errorf("CSVファイルの読み込みに失敗: %w", err)
+		}
+	}
+	if len(tried) == 0 {
+		return nil, fmt.Errorf("%s: %w", f.Name, ErrPasswordRequired)
+	}
+	return nil, fmt.Errorf("%s: %w", f.Name, ErrWrongPassword)
+}
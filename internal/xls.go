@@ -0,0 +1,515 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/richardlehane/mscfb"
+)
+
+// Excel 97-2003形式（BIFF8）のレコード番号
+const (
+	biffFormula     = 0x0006
+	biffEOF         = 0x000A
+	biffDateMode    = 0x0022
+	biffFilePass    = 0x002F
+	biffContinue    = 0x003C
+	biffBoundSheet  = 0x0085
+	biffMulRK       = 0x00BD
+	biffXF          = 0x00E0
+	biffSST         = 0x00FC
+	biffArray       = 0x0221
+	biffTable       = 0x0236
+	biffShrFmla     = 0x04BC
+	biffLabelSST    = 0x00FD
+	biffNumber      = 0x0203
+	biffLabel       = 0x0204
+	biffBoolErr     = 0x0205
+	biffString      = 0x0207
+	biffRK          = 0x027E
+	biffFormat      = 0x041E
+	biffBOF         = 0x0809
+	biffVersion8    = 0x0600
+	biffTypeSheet   = 0x0010 // BOF の種類: ワークシート
+	biffSheetNormal = 0x00   // BOUNDSHEET の種類: ワークシート（マクロシート・グラフは読まない）
+)
+
+// BOUNDSHEET の表示状態: 表示（1は非表示、2は「とても非表示」）
+const biffSheetVisible = 0x00
+
+// olePrefix: 複合ファイル（.xls やパスワード付きのxlsx）の先頭
+var olePrefix = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+
+var (
+	errWorkbookEncrypted = errors.New("パスワードで保護されたファイルです")
+	errXLSEncrypted      = errors.New("パスワードで保護された.xlsファイルには対応していません")
+	errWorkbookCorrupted = errors.New("ファイルが壊れているか、Excelファイルではありません")
+)
+
+// エラー値の表示
+var biffErrors = map[byte]string{
+	0x00: "#NULL!",
+	0x07: "#DIV/0!",
+	0x0F: "#VALUE!",
+	0x17: "#REF!",
+	0x1D: "#NAME?",
+	0x24: "#NUM!",
+	0x2A: "#N/A",
+}
+
+// isOLEFile は複合ファイル形式かどうかを返す
+func isOLEFile(data []byte) bool {
+	return bytes.HasPrefix(data, olePrefix)
+}
+
+// oleStreams は複合ファイルのストリームを名前ごとに読み出す（VBAなどのストレージの中は読まない）
+func oleStreams(data []byte) (map[string][]byte, error) {
+	doc, err := mscfb.New(bytes.NewReader(data))
+	if err != nil {
+		return nil, errWorkbookCorrupted
+	}
+	streams := map[string][]byte{}
+	for entry, err := doc.Next(); err == nil; entry, err = doc.Next() {
+		if len(entry.Path) > 0 || entry.Size == 0 {
+			continue
+		}
+		b, err := io.ReadAll(entry)
+		if err != nil {
+			return nil, errWorkbookCorrupted
+		}
+		streams[entry.Name] = b
+	}
+	return streams, nil
+}
+
+// biffRecord: BIFFのレコード1つ
+type biffRecord struct {
+	typ  uint16
+	data []byte
+}
+
+// biffRecords は offset から EOF レコードまでのレコードを返す
+func biffRecords(stream []byte, offset int) ([]biffRecord, error) {
+	var records []biffRecord
+	for off := offset; off+4 <= len(stream); {
+		typ := binary.LittleEndian.Uint16(stream[off:])
+		n := int(binary.LittleEndian.Uint16(stream[off+2:]))
+		if off+4+n > len(stream) {
+			return nil, errWorkbookCorrupted
+		}
+		records = append(records, biffRecord{typ: typ, data: stream[off+4 : off+4+n]})
+		if typ == biffEOF {
+			return records, nil
+		}
+		off += 4 + n
+	}
+	return nil, errWorkbookCorrupted
+}
+
+// readXLS はExcel 97-2003形式（BIFF8）のファイルの各シートをテンプレートの読み取り範囲の表にする。
+// マクロ（VBAのストリーム）は読まず、セルの値だけを取り出す
+func readXLS(data []byte) (*gridWorkbook, error) {
+	streams, err := oleStreams(data)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := streams["EncryptedPackage"]; ok {
+		return nil, errWorkbookEncrypted
+	}
+	stream, ok := streams["Workbook"]
+	if !ok {
+		if _, ok := streams["Book"]; ok {
+			return nil, fmt.Errorf("Excel 5.0/95形式のファイルには対応していません")
+		}
+		return nil, errWorkbookCorrupted
+	}
+
+	globals, err := biffRecords(stream, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(globals) == 0 || globals[0].typ != biffBOF || len(globals[0].data) < 2 ||
+		binary.LittleEndian.Uint16(globals[0].data) != biffVersion8 {
+		return nil, fmt.Errorf("Excel 97-2003形式（BIFF8）ではありません")
+	}
+
+	type boundSheet struct {
+		name   string
+		pos    int
+		hidden bool
+	}
+	var sheets []boundSheet
+	var sst []string
+	nf := &biffNumFormats{codes: map[int]string{}}
+	for i, r := range globals {
+		switch r.typ {
+		case biffFilePass:
+			return nil, errXLSEncrypted
+		case biffDateMode:
+			nf.date1904 = len(r.data) >= 2 && binary.LittleEndian.Uint16(r.data) == 1
+		case biffFormat:
+			if len(r.data) >= 2 {
+				code, _ := xlString(r.data[2:])
+				nf.codes[int(binary.LittleEndian.Uint16(r.data))] = code
+			}
+		case biffXF:
+			// XF はレコードの順番が番号になる
+			ifmt := -1
+			if len(r.data) >= 4 {
+				ifmt = int(binary.LittleEndian.Uint16(r.data[2:]))
+			}
+			nf.xfs = append(nf.xfs, ifmt)
+		case biffBoundSheet:
+			if len(r.data) < 8 {
+				return nil, errWorkbookCorrupted
+			}
+			if r.data[5] != biffSheetNormal {
+				continue
+			}
+			name, _ := shortXLString(r.data[6:])
+			sheets = append(sheets, boundSheet{
+				name:   name,
+				pos:    int(binary.LittleEndian.Uint32(r.data)),
+				hidden: r.data[4]&0x03 != biffSheetVisible,
+			})
+		case biffSST:
+			segs := [][]byte{r.data}
+			for _, c := range globals[i+1:] {
+				if c.typ != biffContinue {
+					break
+				}
+				segs = append(segs, c.data)
+			}
+			if sst, err = readSST(segs); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	w := newGridWorkbook()
+	for _, s := range sheets {
+		grid, truncated, err := readXLSSheet(stream, s.pos, sst, nf)
+		if err != nil {
+			return nil, fmt.Errorf("シート %s の読み込みに失敗: %w", s.name, err)
+		}
+		w.add(s.name, grid)
+		if truncated {
+			w.addTruncated(s.name)
+		}
+		w.hidden[s.name] = s.hidden
+	}
+	return w, nil
+}
+
+// readXLSSheet はシートのレコードから、読み取り範囲の上限（maxReadRows・maxReadCols）までのセルの値を読み取る。
+// 上限を超える値があった場合は truncated が true
+func readXLSSheet(stream []byte, pos int, sst []string, nf *biffNumFormats) (grid [][]string, truncated bool, err error) {
+	records, err := biffRecords(stream, pos)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(records) == 0 || records[0].typ != biffBOF || len(records[0].data) < 4 ||
+		binary.LittleEndian.Uint16(records[0].data[2:]) != biffTypeSheet {
+		return nil, false, errWorkbookCorrupted
+	}
+
+	var rows [][]string
+	set := func(row, col int, value string) {
+		if row >= maxReadRows || col >= maxReadCols {
+			truncated = truncated || value != ""
+			return
+		}
+		for len(rows) <= row {
+			rows = append(rows, nil)
+		}
+		for len(rows[row]) <= col {
+			rows[row] = append(rows[row], "")
+		}
+		rows[row][col] = value
+	}
+	// 文字列を返す数式は、結果が直後の STRING レコードに入る
+	formulaRow, formulaCol := -1, -1
+
+	for _, r := range records {
+		d := r.data
+		if r.typ == biffString {
+			if formulaRow >= 0 {
+				s, _ := xlString(d)
+				set(formulaRow, formulaCol, s)
+			}
+			formulaRow, formulaCol = -1, -1
+			continue
+		}
+		switch r.typ {
+		case biffContinue, biffShrFmla, biffArray, biffTable:
+			// 共有数式・配列数式・データテーブルは FORMULA と STRING の間に入ることがある
+		default:
+			formulaRow, formulaCol = -1, -1
+		}
+		if len(d) < 6 {
+			continue
+		}
+		row := int(binary.LittleEndian.Uint16(d))
+		col := int(binary.LittleEndian.Uint16(d[2:]))
+		xf := int(binary.LittleEndian.Uint16(d[4:]))
+		switch r.typ {
+		case biffLabelSST:
+			if len(d) >= 10 {
+				if i := int(binary.LittleEndian.Uint32(d[6:])); i < len(sst) {
+					set(row, col, sst[i])
+				}
+			}
+		case biffLabel:
+			s, _ := xlString(d[6:])
+			set(row, col, s)
+		case biffNumber:
+			if len(d) >= 14 {
+				set(row, col, nf.format(xf, math.Float64frombits(binary.LittleEndian.Uint64(d[6:]))))
+			}
+		case biffRK:
+			if len(d) >= 10 {
+				set(row, col, nf.format(xf, decodeRK(binary.LittleEndian.Uint32(d[6:]))))
+			}
+		case biffMulRK:
+			// row, 先頭列, (xf, RK)の繰り返し, 最終列
+			for i, off := 0, 4; off+6 <= len(d)-2; i, off = i+1, off+6 {
+				set(row, col+i, nf.format(int(binary.LittleEndian.Uint16(d[off:])), decodeRK(binary.LittleEndian.Uint32(d[off+2:]))))
+			}
+		case biffBoolErr:
+			if len(d) >= 8 {
+				set(row, col, boolErrValue(d[6], d[7] != 0))
+			}
+		case biffFormula:
+			if len(d) < 14 {
+				continue
+			}
+			result := d[6:14]
+			if result[6] != 0xFF || result[7] != 0xFF {
+				set(row, col, nf.format(xf, math.Float64frombits(binary.LittleEndian.Uint64(result))))
+				continue
+			}
+			switch result[0] {
+			case 0: // 文字列（STRING レコードに続く）
+				formulaRow, formulaCol = row, col
+			case 1:
+				set(row, col, boolErrValue(result[2], false))
+			case 2:
+				set(row, col, boolErrValue(result[2], true))
+			}
+		}
+	}
+	grid, cut := toGrid(trimEmptyRows(rows))
+	return grid, truncated || cut, nil
+}
+
+// decodeRK はRK形式（圧縮した数値）を戻す
+func decodeRK(rk uint32) float64 {
+	var v float64
+	if rk&0x02 != 0 {
+		v = float64(int32(rk) >> 2)
+	} else {
+		v = math.Float64frombits(uint64(rk&0xFFFFFFFC) << 32)
+	}
+	if rk&0x01 != 0 {
+		v /= 100
+	}
+	return v
+}
+
+// biffNumFormats: ブックの表示形式（FORMAT・XF・DATEMODE レコード）
+type biffNumFormats struct {
+	codes    map[int]string // 表示形式の番号ごとのユーザー定義の表示形式
+	xfs      []int          // XF の番号ごとの表示形式の番号
+	date1904 bool
+}
+
+// format は数値を XF の表示形式で整える。xlsx と同じく自前で整えない形式はそのままの数値にする
+func (nf *biffNumFormats) format(xf int, v float64) string {
+	raw := strconv.FormatFloat(v, 'f', -1, 64)
+	if xf < 0 || xf >= len(nf.xfs) {
+		return raw
+	}
+	code, ok := nf.codes[nf.xfs[xf]]
+	if !ok {
+		code = builtInNumFmtCodes[nf.xfs[xf]]
+	}
+	if code == "" {
+		return raw
+	}
+	if formatted, ok := formatCellNumber(raw, code, nf.date1904); ok {
+		return formatted
+	}
+	return raw
+}
+
+func boolErrValue(v byte, isErr bool) string {
+	if isErr {
+		return biffErrors[v]
+	}
+	if v != 0 {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+// decodeBIFFChars は文字列の本体を読む。high が false の場合は1文字1バイト
+func decodeBIFFChars(b []byte, n int, high bool) (string, int) {
+	if !high {
+		if n > len(b) {
+			n = len(b)
+		}
+		u := make([]uint16, n)
+		for i := 0; i < n; i++ {
+			u[i] = uint16(b[i])
+		}
+		return string(utf16.Decode(u)), n
+	}
+	if 2*n > len(b) {
+		n = len(b) / 2
+	}
+	u := make([]uint16, n)
+	for i := 0; i < n; i++ {
+		u[i] = binary.LittleEndian.Uint16(b[2*i:])
+	}
+	return string(utf16.Decode(u)), 2 * n
+}
+
+// shortXLString は文字数が1バイトの文字列（シート名）を読む
+func shortXLString(b []byte) (string, int) {
+	if len(b) < 2 {
+		return "", len(b)
+	}
+	s, n := decodeBIFFChars(b[2:], int(b[0]), b[1]&0x01 != 0)
+	return s, 2 + n
+}
+
+// xlString は文字数が2バイトの文字列（LABEL・STRING レコード）を読む
+func xlString(b []byte) (string, int) {
+	if len(b) < 3 {
+		return "", len(b)
+	}
+	n := int(binary.LittleEndian.Uint16(b))
+	flags := b[2]
+	off := 3
+	if flags&0x08 != 0 {
+		off += 2 // 書式の数
+	}
+	if flags&0x04 != 0 {
+		off += 4 // ふりがなのサイズ
+	}
+	if off > len(b) {
+		return "", len(b)
+	}
+	s, m := decodeBIFFChars(b[off:], n, flags&0x01 != 0)
+	return s, off + m
+}
+
+// sstReader: CONTINUE レコードで分割された共有文字列テーブルを読む
+type sstReader struct {
+	segs [][]byte
+	seg  int
+	pos  int
+}
+
+func (r *sstReader) next() error {
+	r.seg++
+	r.pos = 0
+	if r.seg >= len(r.segs) {
+		return errWorkbookCorrupted
+	}
+	return nil
+}
+
+func (r *sstReader) read(n int) ([]byte, error) {
+	var out []byte
+	for n > 0 {
+		if r.pos >= len(r.segs[r.seg]) {
+			if err := r.next(); err != nil {
+				return nil, err
+			}
+		}
+		m := min(n, len(r.segs[r.seg])-r.pos)
+		out = append(out, r.segs[r.seg][r.pos:r.pos+m]...)
+		r.pos += m
+		n -= m
+	}
+	return out, nil
+}
+
+// chars は n 文字を読む。レコードの境界では次のレコードの先頭に文字の幅（1バイトか2バイトか）が入る
+func (r *sstReader) chars(n int, high bool) (string, error) {
+	u := make([]uint16, 0, n)
+	for len(u) < n {
+		if r.pos >= len(r.segs[r.seg]) {
+			if err := r.next(); err != nil {
+				return "", err
+			}
+			high = r.segs[r.seg][0]&0x01 != 0
+			r.pos = 1
+			continue
+		}
+		seg := r.segs[r.seg]
+		if high {
+			if r.pos+2 > len(seg) {
+				return "", errWorkbookCorrupted
+			}
+			u = append(u, binary.LittleEndian.Uint16(seg[r.pos:]))
+			r.pos += 2
+		} else {
+			u = append(u, uint16(seg[r.pos]))
+			r.pos++
+		}
+	}
+	return string(utf16.Decode(u)), nil
+}
+
+// readSST は共有文字列テーブル（SST と続く CONTINUE レコード）を読む
+func readSST(segs [][]byte) ([]string, error) {
+	r := &sstReader{segs: segs}
+	head, err := r.read(8)
+	if err != nil {
+		return nil, err
+	}
+	count := int(binary.LittleEndian.Uint32(head[4:]))
+	strs := make([]string, 0, min(count, 1<<16))
+	for i := 0; i < count; i++ {
+		h, err := r.read(3)
+		if err != nil {
+			return nil, err
+		}
+		n := int(binary.LittleEndian.Uint16(h))
+		flags := h[2]
+		runs, ext := 0, 0
+		if flags&0x08 != 0 {
+			b, err := r.read(2)
+			if err != nil {
+				return nil, err
+			}
+			runs = int(binary.LittleEndian.Uint16(b))
+		}
+		if flags&0x04 != 0 {
+			b, err := r.read(4)
+			if err != nil {
+				return nil, err
+			}
+			ext = int(binary.LittleEndian.Uint32(b))
+		}
+		s, err := r.chars(n, flags&0x01 != 0)
+		if err != nil {
+			return nil, err
+		}
+		// 書式とふりがなは読み飛ばす
+		if _, err := r.read(4*runs + ext); err != nil {
+			return nil, err
+		}
+		strs = append(strs, strings.ReplaceAll(s, "\r\n", "\n"))
+	}
+	return strs, nil
+}
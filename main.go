@@ -19,8 +19,8 @@ func main() {
 	// Create application with options
 	err := wails.Run(&options.App{
 		Title:  "Job Posting PDF Converter",
-		Width:  350,
-		Height: 500,
+		Width:  520,
+		Height: 760,
 		AssetServer: &assetserver.Options{
 			Assets: assets,
 		},